@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Capital is the capital city of a country and the other names accepted for it.
+type Capital struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// CapitalGuess is the request body for checking a guessed capital. Country may be any name of
+// the country accepted by ResolveGuess.
+type CapitalGuess struct {
+	Country string `json:"country"`
+	Input   string `json:"input"`
+}
+
+// CapitalResolution reports whether a guessed capital is the capital of the given country.
+type CapitalResolution struct {
+	Country string `json:"country"`
+	Input   string `json:"input"`
+	Match   string `json:"match"`
+	Correct bool   `json:"correct"`
+	Capital string `json:"capital"`
+}
+
+// matchCapital checks a submission against the name and aliases of a capital, comparing normalized forms.
+func matchCapital(capital Capital, input string) string {
+	guess := normalizeName(input)
+	if guess == normalizeName(capital.Name) {
+		return matchCanonical
+	}
+	for _, alias := range capital.Aliases {
+		if guess == normalizeName(alias) {
+			return matchAlias
+		}
+	}
+	return matchUnknown
+}
+
+// GetCapitals gets the capital of each accepted country, keyed by country.
+func GetCapitals(writer http.ResponseWriter, request *http.Request) {
+	json.NewEncoder(writer).Encode(currentDataset().Capitals)
+}
+
+// ResolveCapital checks a guessed capital against the capital of a country.
+func ResolveCapital(writer http.ResponseWriter, request *http.Request) {
+	requestBody, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	var guess CapitalGuess
+	err = json.Unmarshal(requestBody, &guess)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	dataset := currentDataset()
+	country := dataset.resolve(guess.Country, negotiateLocale(request, dataset))
+	capital, ok := dataset.Capitals[country.Country]
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no capital for country %s\n", guess.Country)
+		return
+	}
+
+	match := matchCapital(capital, guess.Input)
+	json.NewEncoder(writer).Encode(CapitalResolution{
+		Country: country.Country,
+		Input:   guess.Input,
+		Match:   match,
+		Correct: match != matchUnknown,
+		Capital: capital.Name,
+	})
+}
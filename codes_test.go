@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestGetCodeCountries(t *testing.T) {
+	dataset, err := loadDataset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedDataset.Store(dataset)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/codes/{code}/countries", GetCodeCountries).Methods("GET")
+
+	var want []CodeName
+	for _, country := range dataset.Records {
+		if country.Alpha2 == "us" {
+			want = append(want, CodeName{country.MapName, isSovereign(country.Status)})
+		}
+	}
+	if len(want) < 2 {
+		t.Fatalf("embedded dataset has %d names for us, want a shared code", len(want))
+	}
+
+	for _, code := range []string{"us", "US", "USA", "usa", "840"} {
+		response := serve(router, "GET", "/api/codes/"+code+"/countries", "")
+		if response.Code != http.StatusOK {
+			t.Errorf("%s: status %d: %s", code, response.Code, response.Body)
+			continue
+		}
+		var names []CodeName
+		err := json.NewDecoder(response.Body).Decode(&names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("%s: got %v, want %v", code, names, want)
+		}
+	}
+
+	for _, code := range []string{"zz", "ZZZ", "999"} {
+		response := serve(router, "GET", "/api/codes/"+code+"/countries", "")
+		if response.Code != http.StatusNotFound {
+			t.Errorf("%s: status %d, want %d", code, response.Code, http.StatusNotFound)
+		}
+	}
+}
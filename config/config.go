@@ -0,0 +1,253 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings of the API. Each setting is taken from the first of a command-line
+// flag, an environment variable, the YAML file named by -config or CONFIG_FILE, and its default.
+type Config struct {
+	Port          int           `yaml:"port"`
+	DataDir       string        `yaml:"dataDir"`
+	WatchInterval time.Duration `yaml:"watchInterval"`
+	AdminToken    string        `yaml:"adminToken"`
+	Store         string        `yaml:"store"`
+	SQLitePath    string        `yaml:"sqlitePath"`
+	Migrate       bool          `yaml:"migrate"`
+	Degraded      bool          `yaml:"degraded"`
+	Database      Database      `yaml:"database"`
+	CORS          CORS          `yaml:"cors"`
+}
+
+// Database holds the settings for connecting to Postgres. URL, when set, is used in place of
+// the individual connection settings. A failed connection is retried after RetryBackoff,
+// doubling up to MaxRetryBackoff, until ConnectAttempts have been made. When Degraded, a database
+// still unreachable after them is retried in the background with the same backoff.
+type Database struct {
+	URL             string        `yaml:"url"`
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslmode"`
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnectAttempts int           `yaml:"connectAttempts"`
+	RetryBackoff    time.Duration `yaml:"retryBackoff"`
+	MaxRetryBackoff time.Duration `yaml:"maxRetryBackoff"`
+}
+
+// CORS holds the cross-origin settings.
+type CORS struct {
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() Config {
+	return Config{
+		Port:       8080,
+		Store:      "postgres",
+		SQLitePath: "leaderboard.db",
+		Migrate:    true,
+		Degraded:   true,
+		Database: Database{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxIdleConns:    2,
+			RetryBackoff:    time.Second,
+			MaxRetryBackoff: 30 * time.Second,
+		},
+		CORS: CORS{AllowedOrigins: []string{"*"}},
+	}
+}
+
+// Load registers the configuration flags on flags, parses args and returns the resulting settings
+// and the arguments that are not flags. Flags may come before, between or after the other
+// arguments, up to a "--" argument.
+func Load(flags *flag.FlagSet, args []string) (Config, []string, error) {
+	path := flags.String("config", "", "YAML file of settings, overridden by environment variables and flags")
+	parsed := Default()
+	bind(flags, &parsed)
+	positional, err := parse(flags, args)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	config := Default()
+	if *path == "" {
+		*path = os.Getenv("CONFIG_FILE")
+	}
+	if *path != "" {
+		contents, err := os.ReadFile(*path)
+		if err != nil {
+			return Config{}, nil, err
+		}
+
+		err = yaml.Unmarshal(contents, &config)
+		if err != nil {
+			return Config{}, nil, fmt.Errorf("%s: %v", *path, err)
+		}
+	}
+
+	err = applyEnv(&config)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	overrides := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	bind(overrides, &config)
+	flags.Visit(func(set *flag.Flag) {
+		if overrides.Lookup(set.Name) != nil {
+			overrides.Set(set.Name, set.Value.String())
+		}
+	})
+
+	switch config.Store {
+	case "postgres", "sqlite", "memory":
+	default:
+		return Config{}, nil, fmt.Errorf("unknown leaderboard store %s, expected postgres, sqlite or memory", config.Store)
+	}
+	return config, positional, nil
+}
+
+// parse parses the flags in args, which may be mixed with other arguments, returning the others.
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// bind registers a flag for each setting, storing its value in config.
+func bind(flags *flag.FlagSet, config *Config) {
+	flags.IntVar(&config.Port, "port", config.Port, "port to listen on")
+	flags.StringVar(&config.DataDir, "data-dir", config.DataDir, "directory of data files overriding the embedded country data")
+	flags.DurationVar(&config.WatchInterval, "watch-interval", config.WatchInterval, "how often to check -data-dir for changes to reload, or 0 to disable")
+	flags.StringVar(&config.AdminToken, "admin-token", config.AdminToken, "bearer token required by the admin endpoints, which are disabled when empty")
+	flags.StringVar(&config.Store, "store", config.Store, "where to keep the leaderboard: postgres, sqlite, or memory to lose it on exit")
+	flags.StringVar(&config.SQLitePath, "sqlite-path", config.SQLitePath, "SQLite database file used by -store=sqlite")
+	flags.BoolVar(&config.Migrate, "migrate", config.Migrate, "apply pending leaderboard schema migrations at startup")
+	flags.BoolVar(&config.Degraded, "degraded", config.Degraded, "serve the country endpoints while the database is unreachable, answering leaderboard requests with 503 until it is reached")
+	flags.StringVar(&config.Database.URL, "database-url", config.Database.URL, "Postgres connection URL, used in place of the -db-* connection flags")
+	flags.StringVar(&config.Database.Host, "db-host", config.Database.Host, "Postgres host")
+	flags.IntVar(&config.Database.Port, "db-port", config.Database.Port, "Postgres port")
+	flags.StringVar(&config.Database.User, "db-user", config.Database.User, "Postgres user")
+	flags.StringVar(&config.Database.Name, "db-name", config.Database.Name, "Postgres database name")
+	flags.StringVar(&config.Database.SSLMode, "db-sslmode", config.Database.SSLMode, "Postgres sslmode: disable, require, verify-ca or verify-full")
+	flags.IntVar(&config.Database.MaxOpenConns, "db-max-open-conns", config.Database.MaxOpenConns, "most open database connections, or 0 for no limit")
+	flags.IntVar(&config.Database.MaxIdleConns, "db-max-idle-conns", config.Database.MaxIdleConns, "most idle database connections kept open")
+	flags.DurationVar(&config.Database.ConnMaxLifetime, "db-conn-max-lifetime", config.Database.ConnMaxLifetime, "longest a database connection is reused, or 0 for no limit")
+	flags.IntVar(&config.Database.ConnectAttempts, "db-connect-attempts", config.Database.ConnectAttempts, "attempts to reach the database at startup before giving up, or 0 to keep trying")
+	flags.DurationVar(&config.Database.RetryBackoff, "db-retry-backoff", config.Database.RetryBackoff, "wait before retrying an unreachable database, doubling after each attempt")
+	flags.DurationVar(&config.Database.MaxRetryBackoff, "db-max-retry-backoff", config.Database.MaxRetryBackoff, "longest wait between attempts to reach the database")
+	flags.Var((*listValue)(&config.CORS.AllowedOrigins), "cors-origins", "comma separated origins allowed to call the API, or * for any")
+}
+
+// applyEnv applies the settings given by environment variables.
+func applyEnv(config *Config) error {
+	stringSettings := map[string]*string{
+		"DATABASE_URL": &config.Database.URL,
+		"PGHOST":       &config.Database.Host,
+		"PGUSER":       &config.Database.User,
+		"PGPASSWORD":   &config.Database.Password,
+		"PGDATABASE":   &config.Database.Name,
+		"PGSSLMODE":    &config.Database.SSLMode,
+		"ADMIN_TOKEN":  &config.AdminToken,
+	}
+	for name, value := range stringSettings {
+		if env, ok := os.LookupEnv(name); ok {
+			*value = env
+		}
+	}
+
+	intSettings := map[string]*int{
+		"PORT":   &config.Port,
+		"PGPORT": &config.Database.Port,
+	}
+	for name, value := range intSettings {
+		if env, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.Atoi(env)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			*value = parsed
+		}
+	}
+
+	if env, ok := os.LookupEnv("CORS_ORIGINS"); ok {
+		(*listValue)(&config.CORS.AllowedOrigins).Set(env)
+	}
+	return nil
+}
+
+// ConnectionString returns the Postgres connection string for the settings.
+func (database Database) ConnectionString() string {
+	if database.URL != "" {
+		return database.URL
+	}
+
+	settings := []struct{ key, value string }{
+		{"host", database.Host},
+		{"port", strconv.Itoa(database.Port)},
+		{"user", database.User},
+		{"password", database.Password},
+		{"dbname", database.Name},
+		{"sslmode", database.SSLMode},
+	}
+
+	var parts []string
+	for _, setting := range settings {
+		if setting.value != "" {
+			parts = append(parts, setting.key+"="+quote(setting.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// quote quotes a connection string value containing spaces, quotes or backslashes.
+func quote(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// listValue is a comma separated flag value.
+type listValue []string
+
+func (list *listValue) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *listValue) Set(value string) error {
+	*list = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadFlagsAfterArguments(t *testing.T) {
+	tests := []struct {
+		args       []string
+		store      string
+		positional []string
+	}{
+		{[]string{"-store=sqlite", "migrate", "up"}, "sqlite", []string{"migrate", "up"}},
+		{[]string{"migrate", "up", "-store=sqlite"}, "sqlite", []string{"migrate", "up"}},
+		{[]string{"migrate", "-store", "sqlite", "down", "2"}, "sqlite", []string{"migrate", "down", "2"}},
+		{[]string{"migrate", "--", "up", "-store=sqlite"}, "postgres", []string{"migrate", "up", "-store=sqlite"}},
+		{nil, "postgres", nil},
+	}
+	for _, test := range tests {
+		settings, positional, err := Load(flag.NewFlagSet("api", flag.ContinueOnError), test.args)
+		if err != nil {
+			t.Errorf("%v: %v", test.args, err)
+			continue
+		}
+		if settings.Store != test.store {
+			t.Errorf("%v: store %q, want %q", test.args, settings.Store, test.store)
+		}
+		if !reflect.DeepEqual(positional, test.positional) {
+			t.Errorf("%v: arguments %q, want %q", test.args, positional, test.positional)
+		}
+	}
+}
+
+func TestLoadUnknownStore(t *testing.T) {
+	_, _, err := Load(flag.NewFlagSet("api", flag.ContinueOnError), []string{"-store=bogus"})
+	if err == nil {
+		t.Error("loaded an unknown store")
+	}
+}
+
+// setenv sets an environment variable for the rest of a test, or unsets it when value is empty.
+func setenv(t *testing.T, key, value string) {
+	previous, ok := os.LookupEnv(key)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, previous)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+	if value == "" {
+		os.Unsetenv(key)
+	} else {
+		os.Setenv(key, value)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		env     map[string]string
+		args    []string
+		port    int
+		sslmode string
+	}{
+		{"default", "", nil, nil, 8080, "disable"},
+		{"yaml", "port: 7000\ndatabase:\n  sslmode: require\n", nil, nil, 7000, "require"},
+		{
+			"env over yaml",
+			"port: 7000\ndatabase:\n  sslmode: require\n",
+			map[string]string{"PORT": "7100", "PGSSLMODE": "verify-ca"},
+			nil,
+			7100, "verify-ca",
+		},
+		{
+			"flag over env",
+			"port: 7000\ndatabase:\n  sslmode: require\n",
+			map[string]string{"PORT": "7100", "PGSSLMODE": "verify-ca"},
+			[]string{"-port=7200", "-db-sslmode=verify-full"},
+			7200, "verify-full",
+		},
+		{
+			"flag over yaml",
+			"port: 7000\ndatabase:\n  sslmode: require\n",
+			nil,
+			[]string{"-port=7200", "-db-sslmode=verify-full"},
+			7200, "verify-full",
+		},
+		{
+			"env without yaml",
+			"",
+			map[string]string{"PORT": "7100", "PGSSLMODE": "verify-ca"},
+			nil,
+			7100, "verify-ca",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{"CONFIG_FILE", "PORT", "PGSSLMODE"} {
+				setenv(t, key, test.env[key])
+			}
+			if test.yaml != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				err := os.WriteFile(path, []byte(test.yaml), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				setenv(t, "CONFIG_FILE", path)
+			}
+
+			settings, _, err := Load(flag.NewFlagSet("api", flag.ContinueOnError), test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if settings.Port != test.port {
+				t.Errorf("port %d, want %d", settings.Port, test.port)
+			}
+			if settings.Database.SSLMode != test.sslmode {
+				t.Errorf("sslmode %q, want %q", settings.Database.SSLMode, test.sslmode)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFlagOverConfigFile(t *testing.T) {
+	directory := t.TempDir()
+	ignored := filepath.Join(directory, "ignored.yaml")
+	used := filepath.Join(directory, "used.yaml")
+	for path, contents := range map[string]string{ignored: "port: 7000\n", used: "port: 7300\n"} {
+		err := os.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	setenv(t, "CONFIG_FILE", ignored)
+	setenv(t, "PORT", "")
+
+	settings, _, err := Load(flag.NewFlagSet("api", flag.ContinueOnError), []string{"-config", used})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Port != 7300 {
+		t.Errorf("port %d, want 7300 from the -config file", settings.Port)
+	}
+}
@@ -1,8 +1,23 @@
 package database
 
-import (
-	"database/sql"
-)
+// Entry is the database object for a leaderboard entry.
+type Entry struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Country        string `json:"country"`
+	Countries      int    `json:"countries"`
+	Time           int    `json:"time"`
+	DatasetVersion string `json:"datasetVersion"`
+}
 
-// DBConnection is the connection handle for the database.
-var DBConnection *sql.DB
+// LeaderboardStore stores the leaderboard entries. Entries are listed by countries named, most
+// first, then by time taken, then by id. Update keeps the stored dataset version of an entry
+// given none. Get, Update and Delete return sql.ErrNoRows when there is no entry with the id.
+type LeaderboardStore interface {
+	Get(id int) (Entry, error)
+	List(offset, limit int) ([]Entry, error)
+	Create(entry Entry) (Entry, error)
+	Update(entry Entry) (Entry, error)
+	Delete(id int) (Entry, error)
+	Count() (int, error)
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a LeaderboardStore held in memory, for tests and local runs. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mutex   sync.RWMutex
+	entries map[int]Entry
+	nextID  int
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[int]Entry), nextID: 1}
+}
+
+// Get gets a leaderboard entry by id.
+func (store *MemoryStore) Get(id int) (Entry, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	entry, ok := store.entries[id]
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+// List lists a page of leaderboard entries, breaking ties by id.
+func (store *MemoryStore) List(offset, limit int) ([]Entry, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	entries := make([]Entry, 0, len(store.entries))
+	for _, entry := range store.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Countries != entries[j].Countries {
+			return entries[i].Countries > entries[j].Countries
+		}
+		if entries[i].Time != entries[j].Time {
+			return entries[i].Time < entries[j].Time
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	if offset < 0 || offset > len(entries) {
+		offset = len(entries)
+	}
+	entries = entries[offset:]
+	if limit >= 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Create creates a new leaderboard entry.
+func (store *MemoryStore) Create(entry Entry) (Entry, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	entry.ID = store.nextID
+	store.nextID++
+	store.entries[entry.ID] = entry
+	return entry, nil
+}
+
+// Update updates an existing leaderboard entry, keeping its dataset version when the entry has none.
+func (store *MemoryStore) Update(entry Entry) (Entry, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	existing, ok := store.entries[entry.ID]
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+	if entry.DatasetVersion == "" {
+		entry.DatasetVersion = existing.DatasetVersion
+	}
+	store.entries[entry.ID] = entry
+	return entry, nil
+}
+
+// Delete deletes an existing leaderboard entry.
+func (store *MemoryStore) Delete(id int) (Entry, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	entry, ok := store.entries[id]
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+	delete(store.entries, id)
+	return entry, nil
+}
+
+// Count counts the leaderboard entries.
+func (store *MemoryStore) Count() (int, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return len(store.entries), nil
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+const migrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+// migrationLock is the key of the Postgres advisory lock held while migrating, so that instances
+// starting together do not apply the same migration twice.
+const migrationLock = 4206817
+
+// skipIfPrefix starts a line of an up migration giving a query that counts whether the change
+// is already in the schema, as it is in databases created before migrations were tracked. A
+// nonzero count records the migration as applied without running it.
+const skipIfPrefix = "-- skip if: "
+
+// Migration is a numbered change to the schema, read from the files
+// migrations/<dialect>/<version>_<name>.up.sql and .down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Applied bool
+	up      string
+	down    string
+	skipIf  string
+}
+
+// loadMigrations loads the migrations for a dialect, postgres or sqlite, oldest first.
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := "migrations/" + dialect
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s", dialect)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		parts := strings.SplitN(strings.TrimSuffix(name, ".sql"), "_", 2)
+		if len(parts) != 2 || !strings.HasSuffix(name, ".sql") {
+			return nil, fmt.Errorf("migration %s is not named <version>_<name>.up.sql or .down.sql", name)
+		}
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", name, err)
+		}
+
+		contents, err := fs.ReadFile(migrationFiles, dir+"/"+name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version}
+			byVersion[version] = migration
+		}
+		switch {
+		case strings.HasSuffix(parts[1], ".up"):
+			migration.Name = strings.TrimSuffix(parts[1], ".up")
+			migration.up = string(contents)
+			for _, line := range strings.Split(migration.up, "\n") {
+				if strings.HasPrefix(line, skipIfPrefix) {
+					migration.skipIf = strings.TrimPrefix(line, skipIfPrefix)
+				}
+			}
+		case strings.HasSuffix(parts[1], ".down"):
+			migration.Name = strings.TrimSuffix(parts[1], ".down")
+			migration.down = string(contents)
+		default:
+			return nil, fmt.Errorf("migration %s is not named <version>_<name>.up.sql or .down.sql", name)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d of %s needs both an up and a down file", migration.Version, dialect)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrations lists the migrations for a dialect and whether each has been applied to the database.
+func Migrations(db *sql.DB, dialect string) ([]Migration, error) {
+	conn, err := lock(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(conn, dialect)
+
+	return listMigrations(conn, dialect)
+}
+
+// listMigrations lists the migrations for a dialect, marking those recorded as applied.
+func listMigrations(conn *sql.Conn, dialect string) ([]Migration, error) {
+	migrations, err := loadMigrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	_, err = conn.ExecContext(ctx, migrationsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range migrations {
+		migrations[i].Applied = applied[migrations[i].Version]
+	}
+	return migrations, nil
+}
+
+// MigrateUp applies every migration not yet applied, oldest first, returning those it applied.
+func MigrateUp(db *sql.DB, dialect string) ([]Migration, error) {
+	conn, err := lock(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(conn, dialect)
+
+	migrations, err := listMigrations(conn, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range migrations {
+		if migration.Applied {
+			continue
+		}
+
+		statements := migration.up
+		if migration.skipIf != "" {
+			var count int
+			err = conn.QueryRowContext(context.Background(), migration.skipIf).Scan(&count)
+			if err != nil {
+				return applied, fmt.Errorf("migration %d %s: %v", migration.Version, migration.Name, err)
+			}
+			if count > 0 {
+				statements = ""
+			}
+		}
+		err = runMigration(conn, migration, statements, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2);", migration.Version, migration.Name)
+		if err != nil {
+			return applied, err
+		}
+		migration.Applied = true
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the given number of the most recently applied migrations, returning those it reverted.
+func MigrateDown(db *sql.DB, dialect string, steps int) ([]Migration, error) {
+	conn, err := lock(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(conn, dialect)
+
+	migrations, err := listMigrations(conn, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := migrations[i]
+		if !migration.Applied {
+			continue
+		}
+		err = runMigration(conn, migration, migration.down, "DELETE FROM schema_migrations WHERE version = $1;", migration.Version)
+		if err != nil {
+			return reverted, err
+		}
+		migration.Applied = false
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// runMigration runs one direction of a migration, if it has any statements, and records it in
+// a single transaction.
+func runMigration(conn *sql.Conn, migration Migration, statements, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	if statements != "" {
+		_, err = tx.Exec(statements)
+	}
+	if err == nil {
+		_, err = tx.Exec(record, args...)
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d %s: %v", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+// lock takes a connection for migrating, holding the Postgres advisory lock on it until unlock.
+// SQLite databases are opened with a single connection, which already keeps migrations apart.
+func lock(db *sql.DB, dialect string) (*sql.Conn, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect == "postgres" {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1);", migrationLock)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("locking migrations: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// unlock releases the lock taken by lock and returns the connection to the pool.
+func unlock(conn *sql.Conn, dialect string) {
+	if dialect == "postgres" {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1);", migrationLock)
+	}
+	conn.Close()
+}
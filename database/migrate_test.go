@@ -0,0 +1,90 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateUpDown(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "leaderboard.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	applied, err := MigrateUp(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := loadMigrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("applied %d migrations, want %d", len(applied), len(migrations))
+	}
+
+	applied, err = MigrateUp(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("applied %d migrations again, want none", len(applied))
+	}
+
+	reverted, err := MigrateDown(db, "sqlite", len(migrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != len(migrations) {
+		t.Errorf("reverted %d migrations, want %d", len(reverted), len(migrations))
+	}
+}
+
+func TestMigrateUpUntrackedSchema(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "leaderboard.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The schema created before migrations were tracked, which already has dataset_version.
+	_, err = db.Exec(`CREATE TABLE leaderboard (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		country TEXT NOT NULL,
+		countries INTEGER NOT NULL,
+		time INTEGER NOT NULL,
+		dataset_version TEXT NOT NULL DEFAULT ''
+	);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewSQLStore(db)
+	created, err := store.Create(Entry{Name: "ash", Country: "nz", Countries: 100, Time: 600, DatasetVersion: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = MigrateUp(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := Migrations(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range migrations {
+		if !migration.Applied {
+			t.Errorf("migration %d %s is not applied", migration.Version, migration.Name)
+		}
+	}
+
+	got, err := store.Get(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != created {
+		t.Errorf("got %+v, want %+v", got, created)
+	}
+}
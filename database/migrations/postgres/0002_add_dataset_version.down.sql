@@ -0,0 +1 @@
+ALTER TABLE leaderboard DROP COLUMN dataset_version;
@@ -0,0 +1,3 @@
+-- Leaderboard entries record the version of the country data they were played against,
+-- which the leaderboard queries read and write as dataset_version.
+ALTER TABLE leaderboard ADD COLUMN IF NOT EXISTS dataset_version TEXT NOT NULL DEFAULT '';
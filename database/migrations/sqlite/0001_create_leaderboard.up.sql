@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS leaderboard (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	country TEXT NOT NULL,
+	countries INTEGER NOT NULL,
+	time INTEGER NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Leaderboard entries record the version of the country data they were played against,
+-- which the leaderboard queries read and write as dataset_version.
+-- skip if: SELECT COUNT(*) FROM pragma_table_info('leaderboard') WHERE name = 'dataset_version';
+ALTER TABLE leaderboard ADD COLUMN dataset_version TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"database/sql"
+
+	_ "github.com/lib/pq"
+)
+
+// SQLStore is a LeaderboardStore backed by the leaderboard table in Postgres or SQLite. Its
+// queries are written to run unchanged on both.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore returns a store using an open Postgres or SQLite connection.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db}
+}
+
+// entryColumns lists the leaderboard columns in the order scanEntry reads them.
+const entryColumns = "id, name, country, countries, time, dataset_version"
+
+// Get gets a leaderboard entry by id.
+func (store *SQLStore) Get(id int) (Entry, error) {
+	statement := "SELECT " + entryColumns + " FROM leaderboard WHERE id = $1;"
+	return scanEntry(store.db.QueryRow(statement, id))
+}
+
+// List lists a page of leaderboard entries.
+func (store *SQLStore) List(offset, limit int) ([]Entry, error) {
+	rows, err := store.db.Query("SELECT "+entryColumns+" FROM leaderboard ORDER BY countries DESC, time, id LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries = []Entry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Create creates a new leaderboard entry.
+func (store *SQLStore) Create(entry Entry) (Entry, error) {
+	statement := "INSERT INTO leaderboard (name, country, countries, time, dataset_version) VALUES ($1, $2, $3, $4, $5) RETURNING id;"
+	err := store.db.QueryRow(statement, entry.Name, entry.Country, entry.Countries, entry.Time, entry.DatasetVersion).Scan(&entry.ID)
+	return entry, err
+}
+
+// Update updates an existing leaderboard entry, keeping its dataset version when the entry has none.
+func (store *SQLStore) Update(entry Entry) (Entry, error) {
+	statement := "UPDATE leaderboard set name = $2, country = $3, countries = $4, time = $5, dataset_version = COALESCE(NULLIF($6, ''), dataset_version) where id = $1 RETURNING " + entryColumns + ";"
+	return scanEntry(store.db.QueryRow(statement, entry.ID, entry.Name, entry.Country, entry.Countries, entry.Time, entry.DatasetVersion))
+}
+
+// Delete deletes an existing leaderboard entry.
+func (store *SQLStore) Delete(id int) (Entry, error) {
+	statement := "DELETE FROM leaderboard WHERE id = $1 RETURNING " + entryColumns + ";"
+	return scanEntry(store.db.QueryRow(statement, id))
+}
+
+// Count counts the leaderboard entries.
+func (store *SQLStore) Count() (int, error) {
+	var count int
+	err := store.db.QueryRow("SELECT COUNT(*) FROM leaderboard;").Scan(&count)
+	return count, err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row scanner) (Entry, error) {
+	var entry Entry
+	err := row.Scan(&entry.ID, &entry.Name, &entry.Country, &entry.Countries, &entry.Time, &entry.DatasetVersion)
+	return entry, err
+}
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"database/sql"
+
+	_ "modernc.org/sqlite"
+)
+
+// OpenSQLite opens the SQLite database file at path, creating it if needed.
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows one writer at a time, so share a single connection rather than wait on locks.
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// stores opens each kind of store, empty, for a test.
+func stores(t *testing.T) map[string]LeaderboardStore {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "leaderboard.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = MigrateUp(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]LeaderboardStore{
+		"memory": NewMemoryStore(),
+		"sqlite": NewSQLStore(db),
+	}
+}
+
+func names(entries []Entry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func TestStoreListOrder(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			for _, entry := range []Entry{
+				{Name: "slow", Countries: 150, Time: 900},
+				{Name: "most", Countries: 197, Time: 1200},
+				{Name: "fast", Countries: 150, Time: 600},
+				{Name: "fewest", Countries: 20, Time: 100},
+				{Name: "tied", Countries: 150, Time: 600},
+			} {
+				_, err := store.Create(entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := store.List(0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{"most", "fast", "tied", "slow", "fewest"}
+			if got := names(entries); !reflect.DeepEqual(got, want) {
+				t.Errorf("List(0, 10) = %v, want %v", got, want)
+			}
+
+			entries, err = store.List(1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = []string{"fast", "tied"}
+			if got := names(entries); !reflect.DeepEqual(got, want) {
+				t.Errorf("List(1, 2) = %v, want %v", got, want)
+			}
+
+			entries, err = store.List(5, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("List(5, 10) = %v, want none", names(entries))
+			}
+
+			count, err := store.Count()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 5 {
+				t.Errorf("Count() = %d, want 5", count)
+			}
+		})
+	}
+}
+
+func TestStoreUpdateKeepsDatasetVersion(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			created, err := store.Create(Entry{Name: "ash", Country: "nz", Countries: 100, Time: 600, DatasetVersion: "1.0.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			updated, err := store.Update(Entry{ID: created.ID, Name: "ash", Country: "nz", Countries: 120, Time: 600})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.DatasetVersion != "1.0.0" {
+				t.Errorf("Update without a dataset version gave %q, want %q", updated.DatasetVersion, "1.0.0")
+			}
+
+			updated.DatasetVersion = "1.1.0"
+			updated, err = store.Update(updated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.DatasetVersion != "1.1.0" {
+				t.Errorf("Update with a dataset version gave %q, want %q", updated.DatasetVersion, "1.1.0")
+			}
+		})
+	}
+}
+
+func TestStoreMissingEntry(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			_, err := store.Get(1)
+			if err != sql.ErrNoRows {
+				t.Errorf("Get(1) error = %v, want %v", err, sql.ErrNoRows)
+			}
+			_, err = store.Update(Entry{ID: 1, Name: "nobody"})
+			if err != sql.ErrNoRows {
+				t.Errorf("Update error = %v, want %v", err, sql.ErrNoRows)
+			}
+			_, err = store.Delete(1)
+			if err != sql.ErrNoRows {
+				t.Errorf("Delete(1) error = %v, want %v", err, sql.ErrNoRows)
+			}
+		})
+	}
+}
+
+func TestStoreCreateUpdateDelete(t *testing.T) {
+	for kind, store := range stores(t) {
+		t.Run(kind, func(t *testing.T) {
+			created, err := store.Create(Entry{Name: "ash", Country: "nz", Countries: 100, Time: 600, DatasetVersion: "1.0.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.ID == 0 {
+				t.Fatalf("Create gave no id")
+			}
+
+			created.Countries = 120
+			updated, err := store.Update(created)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated != created {
+				t.Errorf("Update = %+v, want %+v", updated, created)
+			}
+
+			got, err := store.Get(created.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != updated {
+				t.Errorf("Get = %+v, want %+v", got, updated)
+			}
+
+			deleted, err := store.Delete(created.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != updated {
+				t.Errorf("Delete = %+v, want %+v", deleted, updated)
+			}
+			_, err = store.Get(created.ID)
+			if err != sql.ErrNoRows {
+				t.Errorf("Get after Delete error = %v, want %v", err, sql.ErrNoRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+//go:embed data
+var embeddedData embed.FS
+
+// loadedDataset holds the *Dataset currently served by the API.
+var loadedDataset atomic.Value
+
+// currentDataset returns the country data currently served by the API.
+func currentDataset() *Dataset {
+	return loadedDataset.Load().(*Dataset)
+}
+
+// Dataset holds the country records loaded from the data files and the tables derived from them.
+type Dataset struct {
+	Records         []Country
+	Countries       []string
+	Aliases         []Alias
+	Prefixes        map[string]string
+	PrefixIndex     map[string]string
+	PrefixConflicts []PrefixConflict
+	KnownPrefixes   map[string]string
+	CountriesMap    map[string]string
+	Codes           map[string]string
+	Regions         []Region
+	Capitals        map[string]Capital
+	Flags           map[string]Flag
+	Borders         map[string][]string
+	WorldMap        *WorldMap
+	Version         DatasetVersion
+	Translations    map[string]Locale
+	Locales         map[string]*LocalizedNames
+}
+
+// loadDataset loads the data files embedded in the binary, replacing any of them with the
+// file of the same name in overrideDir when one exists.
+func loadDataset(overrideDir string) (*Dataset, error) {
+	var records []Country
+	err := readDataFile(overrideDir, "countries.json", &records)
+	if err != nil {
+		return nil, err
+	}
+
+	var knownPrefixes map[string]string
+	err = readDataFile(overrideDir, "prefixes.json", &knownPrefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []Region
+	err = readDataFile(overrideDir, "regions.json", &regions)
+	if err != nil {
+		return nil, err
+	}
+
+	var capitals map[string]Capital
+	err = readDataFile(overrideDir, "capitals.json", &capitals)
+	if err != nil {
+		return nil, err
+	}
+
+	localeFiles, err := listDataFiles(overrideDir, "locales", ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make(map[string]Locale)
+	for _, name := range localeFiles {
+		var locale Locale
+		err = readDataFile(overrideDir, "locales/"+name, &locale)
+		if err != nil {
+			return nil, err
+		}
+		translations[strings.TrimSuffix(name, ".json")] = locale
+	}
+
+	var version DatasetVersion
+	err = readDataFile(overrideDir, "version.json", &version)
+	if err != nil {
+		return nil, err
+	}
+
+	var borders map[string][]string
+	err = readDataFile(overrideDir, "borders.json", &borders)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := loadFlags(overrideDir)
+	if err != nil {
+		return nil, err
+	}
+
+	worldMap, err := loadWorldMap(overrideDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := newDataset(records, knownPrefixes, translations)
+	countRegions(regions, records)
+	dataset.Regions = regions
+	dataset.Capitals = capitals
+	dataset.Flags = flags
+	dataset.Borders = borders
+	dataset.WorldMap = worldMap
+	dataset.Version = version
+	return dataset, nil
+}
+
+func readDataFile(overrideDir, name string, value interface{}) error {
+	contents, err := readOverridableFile(overrideDir, "data/"+name)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(contents, value)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// readOverridableFile reads a file from overrideDir if present, otherwise from the embedded data.
+func readOverridableFile(overrideDir, name string) ([]byte, error) {
+	if overrideDir != "" {
+		contents, err := os.ReadFile(filepath.Join(overrideDir, strings.TrimPrefix(name, "data/")))
+		if err == nil {
+			return contents, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return fs.ReadFile(embeddedData, name)
+}
+
+// listDataFiles lists the files with an extension in a data directory, including any only present in overrideDir.
+func listDataFiles(overrideDir, dir, extension string) ([]string, error) {
+	found := make(map[string]bool)
+	entries, err := fs.ReadDir(embeddedData, "data/"+dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		found[entry.Name()] = true
+	}
+
+	if overrideDir != "" {
+		entries, err = os.ReadDir(filepath.Join(overrideDir, dir))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			found[entry.Name()] = true
+		}
+	}
+
+	var names []string
+	for name := range found {
+		if strings.HasSuffix(name, extension) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func newDataset(records []Country, knownPrefixes map[string]string, translations map[string]Locale) *Dataset {
+	english := newLocalizedNames()
+	dataset := &Dataset{
+		Records:       records,
+		KnownPrefixes: knownPrefixes,
+		Codes:         make(map[string]string, len(records)),
+		Translations:  translations,
+		Locales:       map[string]*LocalizedNames{defaultLocale: english},
+	}
+
+	for _, country := range records {
+		dataset.Codes[country.MapName] = country.Alpha2
+		for _, alias := range country.Aliases {
+			alias.Country = country.Key
+			dataset.Aliases = append(dataset.Aliases, alias)
+		}
+
+		if !country.Accepted {
+			continue
+		}
+		english.Countries = append(english.Countries, country.Key)
+		english.CountriesMap[country.Key] = country.MapName
+		english.Canonical[normalizeName(country.Key)] = country.Key
+		for _, alias := range country.Aliases {
+			english.CountriesMap[alias.Name] = country.MapName
+			english.Aliases[normalizeName(alias.Name)] = country.Key
+		}
+	}
+	dataset.Countries = english.Countries
+	dataset.CountriesMap = english.CountriesMap
+
+	for locale, translation := range translations {
+		dataset.Locales[locale] = localizeNames(records, translation)
+	}
+
+	dataset.PrefixConflicts = findPrefixConflicts(dataset.acceptedNames(defaultLocale), dataset.CountriesMap, knownPrefixes)
+	dataset.Prefixes = prefixTable(dataset.PrefixConflicts)
+	dataset.PrefixIndex = make(map[string]string, len(dataset.Prefixes))
+	for prefix, mapName := range dataset.Prefixes {
+		dataset.PrefixIndex[normalizeName(prefix)] = mapName
+	}
+
+	return dataset
+}
+
+func (dataset *Dataset) acceptedRecords() []Country {
+	var records []Country
+	for _, country := range dataset.Records {
+		if country.Accepted {
+			records = append(records, country)
+		}
+	}
+	return records
+}
+
+// recordsWithStatus gets the records with any of a comma separated list of statuses, or the
+// accepted records when the list is empty.
+func (dataset *Dataset) recordsWithStatus(list string) ([]Country, error) {
+	if list == "" {
+		return dataset.acceptedRecords(), nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, status := range strings.Split(list, ",") {
+		status = strings.TrimSpace(status)
+		if !isStatus(status) {
+			return nil, fmt.Errorf("unknown status %s", status)
+		}
+		wanted[status] = true
+	}
+
+	records := []Country{}
+	for _, country := range dataset.Records {
+		if wanted[country.Status] {
+			records = append(records, country)
+		}
+	}
+	return records, nil
+}
+
+// findCountry finds the record for an ISO-3166 alpha-2, alpha-3 or numeric code, preferring
+// accepted countries where an alpha-2 code is shared.
+func (dataset *Dataset) findCountry(code string) (Country, bool) {
+	matches := func(country Country) bool {
+		return country.Alpha2 == strings.ToLower(code)
+	}
+	if isNumericCode(code) {
+		code = fmt.Sprintf("%03s", code)
+		matches = func(country Country) bool {
+			return country.Numeric == code
+		}
+	} else if len(code) == 3 {
+		matches = func(country Country) bool {
+			return country.Alpha3 == strings.ToUpper(code)
+		}
+	}
+
+	var found Country
+	var ok bool
+	for _, country := range dataset.Records {
+		if !matches(country) {
+			continue
+		}
+		if country.Accepted {
+			return country, true
+		}
+		if !ok {
+			found, ok = country, true
+		}
+	}
+	return found, ok
+}
+
+func isNumericCode(code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Flag is the SVG flag of the countries with an alpha-2 code.
+type Flag struct {
+	Contents []byte
+	ETag     string
+}
+
+// loadFlags loads the SVG flags in data/flags, named by lowercase alpha-2 code such as nz.svg.
+func loadFlags(overrideDir string) (map[string]Flag, error) {
+	names, err := listDataFiles(overrideDir, "flags", ".svg")
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make(map[string]Flag, len(names))
+	for _, name := range names {
+		contents, err := readOverridableFile(overrideDir, "data/flags/"+name)
+		if err != nil {
+			return nil, err
+		}
+		flags[strings.TrimSuffix(name, ".svg")] = Flag{contents, fmt.Sprintf(`"%x"`, sha256.Sum256(contents))}
+	}
+	return flags, nil
+}
+
+// GetFlag gets the SVG flag of the country with an ISO-3166 alpha-2, alpha-3 or numeric code.
+func GetFlag(writer http.ResponseWriter, request *http.Request) {
+	dataset := currentDataset()
+	code := mux.Vars(request)["code"]
+	country, ok := dataset.findCountry(code)
+	flag, hasFlag := dataset.Flags[country.Alpha2]
+	if !ok || !hasFlag {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no flag for code %s\n", code)
+		return
+	}
+
+	// Flags can be replaced through the data directory, so clients revalidate them by ETag daily.
+	serveSVG(writer, request, code+".svg", flag.Contents, flag.ETag, "public, max-age=86400")
+}
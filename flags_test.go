@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestGetFlagRevalidates(t *testing.T) {
+	dataset := fixtureDataset(fixtureCountry("new zealand", "New Zealand", "nz", "NZL", "554"))
+	dataset.Flags["nz"] = Flag{[]byte("<svg/>"), `"nz-1"`}
+	loadedDataset.Store(dataset)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/flags/{code}.svg", GetFlag).Methods("GET")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/flags/NZL.svg", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", recorder.Code, http.StatusOK)
+	}
+	if cacheControl := recorder.Header().Get("Cache-Control"); cacheControl != "public, max-age=86400" {
+		t.Errorf("Cache-Control %q, want revalidation after a day", cacheControl)
+	}
+	if etag := recorder.Header().Get("ETag"); etag != `"nz-1"` {
+		t.Errorf("ETag %q, want %q", etag, `"nz-1"`)
+	}
+
+	request := httptest.NewRequest("GET", "/api/flags/nz.svg", nil)
+	request.Header.Set("If-None-Match", `"nz-1"`)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotModified {
+		t.Errorf("unchanged flag: status %d, want %d", recorder.Code, http.StatusNotModified)
+	}
+
+	dataset.Flags["nz"] = Flag{[]byte("<svg></svg>"), `"nz-2"`}
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("replaced flag: status %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFuzzyDistance is the largest edit distance fuzzy matching allows, reached only by long names.
+const maxFuzzyDistance = 3
+
+// Candidate is a country whose name is within the allowed edit distance of a submission.
+type Candidate struct {
+	Country  string  `json:"country"`
+	Name     string  `json:"name"`
+	Distance int     `json:"distance"`
+	Score    float64 `json:"score"`
+}
+
+// fuzzyThreshold scales the allowed edit distance with the length of the name being matched,
+// so short names such as "iran" and "iraq" must match exactly.
+func fuzzyThreshold(name string, maxDistance int) int {
+	threshold := 0
+	switch length := utf8.RuneCountInString(name); {
+	case length >= 12:
+		threshold = 3
+	case length >= 8:
+		threshold = 2
+	case length >= 5:
+		threshold = 1
+	}
+
+	if maxDistance <= 0 || maxDistance > maxFuzzyDistance {
+		maxDistance = maxFuzzyDistance
+	}
+	if threshold > maxDistance {
+		return maxDistance
+	}
+	return threshold
+}
+
+// editDistance is the optimal string alignment distance between two strings: the number of
+// insertions, deletions, substitutions and transpositions of adjacent runes needed to turn a into b.
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	rows := make([][]int, len(source)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(target)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			distance := minimum(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				distance = minimum(distance, rows[i-2][j-2]+1)
+			}
+			rows[i][j] = distance
+		}
+	}
+	return rows[len(source)][len(target)]
+}
+
+func minimum(values ...int) int {
+	smallest := values[0]
+	for _, value := range values[1:] {
+		if value < smallest {
+			smallest = value
+		}
+	}
+	return smallest
+}
+
+// fuzzyCandidates finds the closest name of each country within the allowed edit distance of a
+// normalized submission, best first.
+func (dataset *Dataset) fuzzyCandidates(guess, locale string, maxDistance int) []Candidate {
+	best := make(map[string]Candidate)
+	consider := func(name, country string) {
+		distance := editDistance(guess, name)
+		if distance > fuzzyThreshold(name, maxDistance) {
+			return
+		}
+		if candidate, ok := best[country]; ok && candidate.Distance <= distance {
+			return
+		}
+
+		length := utf8.RuneCountInString(name)
+		if guessLength := utf8.RuneCountInString(guess); guessLength > length {
+			length = guessLength
+		}
+		best[country] = Candidate{country, name, distance, 1 - float64(distance)/float64(length)}
+	}
+
+	locales := []string{defaultLocale}
+	if locale != defaultLocale {
+		locales = []string{locale, defaultLocale}
+	}
+	for _, locale := range locales {
+		names, ok := dataset.Locales[locale]
+		if !ok {
+			continue
+		}
+		for name, country := range names.Canonical {
+			consider(name, country)
+		}
+		for name, country := range names.Aliases {
+			consider(name, country)
+		}
+	}
+
+	candidates := make([]Candidate, 0, len(best))
+	for _, candidate := range best {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		return candidates[i].Country < candidates[j].Country
+	})
+	return candidates
+}
+
+// resolveFuzzy resolves a submission like resolve, falling back to the closest country name
+// when there is no exact match. A fuzzy match is only made when a single country is within the
+// allowed distance and the submission does not extend a name that is a prefix of another
+// country, so a typo is never corrected into a different real country such as "niger" for
+// "nigeria".
+func (dataset *Dataset) resolveFuzzy(input, locale string, maxDistance int) Resolution {
+	resolution := dataset.resolve(input, locale)
+	if resolution.Match != matchUnknown {
+		return resolution
+	}
+
+	guess := normalizeName(input)
+	candidates := dataset.fuzzyCandidates(guess, locale, maxDistance)
+	resolution.Candidates = candidates
+	if len(candidates) != 1 {
+		return resolution
+	}
+
+	name := candidates[0].Name
+	if _, ok := dataset.PrefixIndex[name]; ok && strings.HasPrefix(guess, name) {
+		return resolution
+	}
+
+	return dataset.matched(resolution, matchFuzzy, candidates[0].Country, locale)
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestFuzzyThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxDistance int
+		threshold   int
+	}{
+		{"iran", 0, 0},
+		{"chad", 0, 0},
+		{"spain", 0, 1},
+		{"nigeria", 0, 1},
+		{"malaysia", 0, 2},
+		{"kazakhstan", 0, 2},
+		{"south africa", 0, 3},
+		{"papua new guinea", 0, 3},
+		{"papua new guinea", 1, 1},
+		{"papua new guinea", 5, 3},
+		{"spain", 3, 1},
+	}
+	for _, test := range tests {
+		threshold := fuzzyThreshold(test.name, test.maxDistance)
+		if threshold != test.threshold {
+			t.Errorf("fuzzyThreshold(%q, %d) = %d, want %d", test.name, test.maxDistance, threshold, test.threshold)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"iran", "iran", 0},
+		{"iran", "iraq", 1},
+		{"kazakstan", "kazakhstan", 1},
+		{"phillipines", "philippines", 2},
+		{"nigera", "nigeria", 1},
+		{"nigeira", "nigeria", 1},
+		{"itlay", "italy", 1},
+		{"", "chad", 4},
+		{"côte", "cote", 1},
+	}
+	for _, test := range tests {
+		distance := editDistance(test.a, test.b)
+		if distance != test.distance {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, distance, test.distance)
+		}
+	}
+}
+
+func TestResolveFuzzy(t *testing.T) {
+	dataset, err := loadDataset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input   string
+		match   string
+		country string
+	}{
+		{"phillipines", matchFuzzy, "philippines"},
+		{"kazakstan", matchFuzzy, "kazakhstan"},
+		{"itlay", matchFuzzy, "italy"},
+		{"argentnia", matchFuzzy, "argentina"},
+		{"nigera", matchUnknown, ""},
+		{"nigerr", matchUnknown, ""},
+		{"dominican", matchUnknown, ""},
+		{"iran", matchCanonical, "iran"},
+		{"iraq", matchCanonical, "iraq"},
+		{"irun", matchUnknown, ""},
+		{"irak", matchUnknown, ""},
+		{"nigeria", matchCanonical, "nigeria"},
+	}
+	for _, test := range tests {
+		resolution := dataset.resolveFuzzy(test.input, defaultLocale, 0)
+		if resolution.Match != test.match || resolution.Country != test.country {
+			t.Errorf("resolveFuzzy(%q) = %s %q, want %s %q", test.input, resolution.Match, resolution.Country, test.match, test.country)
+		}
+	}
+}
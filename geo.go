@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Geo locates a country for zooming the map: its centroid, bounding box and land area in square kilometres.
+type Geo struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Bounds    Bounds  `json:"bounds"`
+	Area      float64 `json:"area"`
+}
+
+// Bounds is a bounding box in degrees. A box crossing the antimeridian, such as that of Fiji,
+// has a MinLongitude greater than its MaxLongitude.
+type Bounds struct {
+	MinLatitude  float64 `json:"minLatitude"`
+	MinLongitude float64 `json:"minLongitude"`
+	MaxLatitude  float64 `json:"maxLatitude"`
+	MaxLongitude float64 `json:"maxLongitude"`
+}
+
+// contains reports whether a point lies inside the bounding box.
+func (bounds Bounds) contains(latitude, longitude float64) bool {
+	if latitude < bounds.MinLatitude || latitude > bounds.MaxLatitude {
+		return false
+	}
+	if bounds.MinLongitude > bounds.MaxLongitude {
+		return longitude >= bounds.MinLongitude || longitude <= bounds.MaxLongitude
+	}
+	return longitude >= bounds.MinLongitude && longitude <= bounds.MaxLongitude
+}
+
+// longitudeSpan returns the degrees of longitude the bounding box covers, going east from
+// MinLongitude to MaxLongitude.
+func (bounds Bounds) longitudeSpan() float64 {
+	if bounds.MinLongitude > bounds.MaxLongitude {
+		return 360 - bounds.MinLongitude + bounds.MaxLongitude
+	}
+	return bounds.MaxLongitude - bounds.MinLongitude
+}
+
+// GetGeo gets the centroid, bounding box and area of the country with an ISO-3166 code.
+func GetGeo(writer http.ResponseWriter, request *http.Request) {
+	code := mux.Vars(request)["code"]
+	country, ok := currentDataset().findCountry(code)
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no country with code %s\n", code)
+		return
+	}
+	json.NewEncoder(writer).Encode(country.Geo)
+}
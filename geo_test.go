@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestBoundsAcrossAntimeridian(t *testing.T) {
+	dataset, err := loadDataset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		code      string
+		place     string
+		latitude  float64
+		longitude float64
+		inside    bool
+	}{
+		{"ru", "Kaliningrad", 54.71, 20.51, true},
+		{"ru", "Petropavlovsk-Kamchatsky", 53.02, 158.65, true},
+		{"ru", "Cape Dezhnev", 66.08, -169.65, true},
+		{"ru", "Franz Josef Land", 81.0, 55.0, true},
+		{"ru", "Nome", 64.5, -165.4, false},
+		{"ru", "North Pole", 89.0, 0, false},
+		{"fj", "Suva", -18.14, 178.44, true},
+		{"fj", "Lakeba", -18.2, -178.8, true},
+		{"fj", "Nukuʻalofa", -21.14, -175.2, false},
+		{"ki", "Tarawa", 1.33, 173.0, true},
+		{"ki", "Kiritimati", 1.87, -157.4, true},
+		{"ki", "Honolulu", 21.31, -157.86, false},
+		{"nz", "Wellington", -41.29, 174.78, true},
+		{"nz", "Chatham Islands", -43.95, -176.56, true},
+		{"us", "Attu Island", 52.9, 172.9, true},
+		{"us", "Washington", 38.9, -77.04, true},
+		{"us", "Petropavlovsk-Kamchatsky", 53.02, 158.65, false},
+		{"to", "Nukuʻalofa", -21.14, -175.2, true},
+		{"to", "Vavaʻu", -18.65, -174.0, true},
+		{"to", "Tahiti", -17.65, -149.43, false},
+	}
+	for _, test := range tests {
+		country, ok := dataset.findCountry(test.code)
+		if !ok {
+			t.Fatalf("no country with code %s", test.code)
+		}
+		if country.Geo.Bounds.contains(test.latitude, test.longitude) != test.inside {
+			t.Errorf("%s bounds %+v contain %s: %v, want %v", test.code, country.Geo.Bounds, test.place, !test.inside, test.inside)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 module github.com/ashmidgley/countries-of-the-world-api
 
-go 1.15
+go 1.16
 
 require (
 	github.com/gorilla/mux v1.8.0
 	github.com/lib/pq v1.8.0
 	github.com/rs/cors v1.7.0
+	golang.org/x/text v0.3.8
+	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.17.3
 )
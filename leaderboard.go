@@ -3,33 +3,80 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"sync/atomic"
+	"time"
 
+	"github.com/ashmidgley/countries-of-the-world-api/config"
 	"github.com/ashmidgley/countries-of-the-world-api/database"
 	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
 )
 
-// Entry is the database object for a leaderboard entry.
-type Entry struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	Country   string `json:"country"`
-	Countries int    `json:"countries"`
-	Time      int    `json:"time"`
-}
+// pageSize is the number of leaderboard entries in a page.
+const pageSize = 10
 
 // EntriesDto is used to display a paged result of leaderboard entries.
 type EntriesDto struct {
-	Entries []Entry `json:"entries"`
-	HasMore bool    `json:"hasMore"`
+	Entries []database.Entry `json:"entries"`
+	HasMore bool             `json:"hasMore"`
+}
+
+// leaderboardHandler serves the leaderboard from a store.
+type leaderboardHandler struct {
+	store atomic.Value // database.LeaderboardStore, unset until the database is reachable
+}
+
+// open opens the configured store and starts serving the leaderboard from it.
+func (leaderboard *leaderboardHandler) open(settings config.Config) error {
+	store, err := openStore(settings)
+	if err != nil {
+		return err
+	}
+
+	leaderboard.store.Store(store)
+	return nil
+}
+
+// openInBackground keeps trying to open the store while the database is unreachable, waiting
+// between attempts with the same doubling backoff as the database connection. It returns any
+// other error, such as a failed migration, which retrying would not fix.
+func (leaderboard *leaderboardHandler) openInBackground(settings config.Config) error {
+	backoff := settings.Database.RetryBackoff
+	for {
+		err := leaderboard.open(settings)
+		if !errors.Is(err, errUnreachable) {
+			return err
+		}
+
+		fmt.Printf("Leaderboard unavailable, retrying in %v: %v\n", backoff, err)
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff, settings.Database)
+	}
+}
+
+func (leaderboard *leaderboardHandler) currentStore() database.LeaderboardStore {
+	store, _ := leaderboard.store.Load().(database.LeaderboardStore)
+	return store
+}
+
+// available answers 503 Service Unavailable in place of a leaderboard handler until the store is open.
+func (leaderboard *leaderboardHandler) available(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if leaderboard.currentStore() == nil {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(writer, "leaderboard database unavailable\n")
+			return
+		}
+		handler(writer, request)
+	}
 }
 
 // GetEntry gets a leaderboard entry by id.
-func GetEntry(writer http.ResponseWriter, request *http.Request) {
+func (leaderboard *leaderboardHandler) GetEntry(writer http.ResponseWriter, request *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(request)["id"])
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
@@ -37,78 +84,45 @@ func GetEntry(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	statement := "SELECT * FROM leaderboard WHERE id = $1;"
-	row := database.DBConnection.QueryRow(statement, id)
-
-	var entry Entry
-	switch err = row.Scan(&entry.ID, &entry.Name, &entry.Country, &entry.Countries, &entry.Time); err {
-	case sql.ErrNoRows:
-		writer.WriteHeader(http.StatusNotFound)
-		fmt.Fprintf(writer, "%v\n", err)
-	case nil:
-		json.NewEncoder(writer).Encode(entry)
-	default:
-		writer.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(writer, "%v\n", err)
-	}
+	entry, err := leaderboard.currentStore().Get(id)
+	writeEntry(writer, entry, err)
 }
 
 // GetEntries gets the leaderboard entries for a given page.
-func GetEntries(writer http.ResponseWriter, request *http.Request) {
+func (leaderboard *leaderboardHandler) GetEntries(writer http.ResponseWriter, request *http.Request) {
 	pageParam := request.URL.Query().Get("page")
 	page, err := strconv.Atoi(pageParam)
 	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(writer, "%v\n", err)
 		return
 	}
+	if page < 0 {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "page %d is negative\n", page)
+		return
+	}
 
-	rows, err := database.DBConnection.Query("SELECT * FROM leaderboard ORDER BY countries DESC, time LIMIT $1 OFFSET $2;", 10, strconv.Itoa(page*10))
+	entries, err := leaderboard.currentStore().List(page*pageSize, pageSize)
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(writer, "%v\n", err)
 		return
 	}
-	defer rows.Close()
 
-	var entries = []Entry{}
-	for rows.Next() {
-		var entry Entry
-		err = rows.Scan(&entry.ID, &entry.Name, &entry.Country, &entry.Countries, &entry.Time)
-		if err != nil {
-			writer.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(writer, "%v\n", err)
-			return
-		}
-
-		entries = append(entries, entry)
-	}
-
-	err = rows.Err()
+	count, err := leaderboard.currentStore().Count()
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(writer, "%v\n", err)
 		return
 	}
 
-	statement := "SELECT id FROM leaderboard ORDER BY countries DESC, time LIMIT $1 OFFSET $2;"
-	row := database.DBConnection.QueryRow(statement, 1, strconv.Itoa((page+1)*10))
-
-	var id int
-	switch err = row.Scan(&id); err {
-	case sql.ErrNoRows:
-		entriesDto := EntriesDto{entries, false}
-		json.NewEncoder(writer).Encode(entriesDto)
-	case nil:
-		entriesDto := EntriesDto{entries, true}
-		json.NewEncoder(writer).Encode(entriesDto)
-	default:
-		writer.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(writer, "%v\n", err)
-	}
+	entriesDto := EntriesDto{entries, (page+1)*pageSize < count}
+	json.NewEncoder(writer).Encode(entriesDto)
 }
 
 // CreateEntry creates a new leaderboard entry.
-func CreateEntry(writer http.ResponseWriter, request *http.Request) {
+func (leaderboard *leaderboardHandler) CreateEntry(writer http.ResponseWriter, request *http.Request) {
 	requestBody, err := ioutil.ReadAll(request.Body)
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
@@ -116,7 +130,7 @@ func CreateEntry(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	var newEntry Entry
+	var newEntry database.Entry
 	err = json.Unmarshal(requestBody, &newEntry)
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
@@ -124,10 +138,11 @@ func CreateEntry(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	statement := "INSERT INTO leaderboard (name, country, countries, time) VALUES ($1, $2, $3, $4) RETURNING id;"
+	if newEntry.DatasetVersion == "" {
+		newEntry.DatasetVersion = currentDataset().Version.Version
+	}
 
-	var id int
-	err = database.DBConnection.QueryRow(statement, newEntry.Name, newEntry.Country, newEntry.Countries, newEntry.Time).Scan(&id)
+	newEntry, err = leaderboard.currentStore().Create(newEntry)
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(writer, "%v\n", err)
@@ -135,12 +150,11 @@ func CreateEntry(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	writer.WriteHeader(http.StatusCreated)
-	newEntry.ID = id
 	json.NewEncoder(writer).Encode(newEntry)
 }
 
 // UpdateEntry updates an existing leaderboard entry.
-func UpdateEntry(writer http.ResponseWriter, request *http.Request) {
+func (leaderboard *leaderboardHandler) UpdateEntry(writer http.ResponseWriter, request *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(request)["id"])
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
@@ -155,7 +169,7 @@ func UpdateEntry(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	var updatedEntry Entry
+	var updatedEntry database.Entry
 	err = json.Unmarshal(requestBody, &updatedEntry)
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
@@ -163,25 +177,13 @@ func UpdateEntry(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	statement := "UPDATE leaderboard set name = $2, country = $3, countries = $4, time = $5 where id = $1 RETURNING *;"
-
-	row := database.DBConnection.QueryRow(statement, id, updatedEntry.Name, updatedEntry.Country, updatedEntry.Countries, updatedEntry.Time)
-
-	var entry Entry
-	switch err = row.Scan(&entry.ID, &entry.Name, &entry.Country, &entry.Countries, &entry.Time); err {
-	case sql.ErrNoRows:
-		writer.WriteHeader(http.StatusNotFound)
-		fmt.Fprintf(writer, "%v\n", err)
-	case nil:
-		json.NewEncoder(writer).Encode(entry)
-	default:
-		writer.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(writer, "%v\n", err)
-	}
+	updatedEntry.ID = id
+	entry, err := leaderboard.currentStore().Update(updatedEntry)
+	writeEntry(writer, entry, err)
 }
 
 // DeleteEntry deletes an existing leaderboard entry.
-func DeleteEntry(writer http.ResponseWriter, request *http.Request) {
+func (leaderboard *leaderboardHandler) DeleteEntry(writer http.ResponseWriter, request *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(request)["id"])
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
@@ -189,11 +191,13 @@ func DeleteEntry(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	statement := "DELETE FROM leaderboard WHERE id = $1 RETURNING *;"
-	row := database.DBConnection.QueryRow(statement, id)
+	entry, err := leaderboard.currentStore().Delete(id)
+	writeEntry(writer, entry, err)
+}
 
-	var entry Entry
-	switch err = row.Scan(&entry.ID, &entry.Name, &entry.Country, &entry.Countries, &entry.Time); err {
+// writeEntry writes an entry returned by the store, or the error looking it up.
+func writeEntry(writer http.ResponseWriter, entry database.Entry, err error) {
+	switch err {
 	case sql.ErrNoRows:
 		writer.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(writer, "%v\n", err)
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashmidgley/countries-of-the-world-api/config"
+	"github.com/ashmidgley/countries-of-the-world-api/database"
+	"github.com/gorilla/mux"
+)
+
+// leaderboardRouter routes the leaderboard endpoints to leaderboard as main does.
+func leaderboardRouter(leaderboard *leaderboardHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/leaderboard", leaderboard.available(leaderboard.GetEntries)).Methods("GET")
+	router.HandleFunc("/api/leaderboard/{id}", leaderboard.available(leaderboard.GetEntry)).Methods("GET")
+	router.HandleFunc("/api/leaderboard", leaderboard.available(leaderboard.CreateEntry)).Methods("POST")
+	router.HandleFunc("/api/leaderboard/{id}", leaderboard.available(leaderboard.UpdateEntry)).Methods("PUT")
+	router.HandleFunc("/api/leaderboard/{id}", leaderboard.available(leaderboard.DeleteEntry)).Methods("DELETE")
+	return router
+}
+
+// memoryLeaderboard loads the embedded dataset and returns a leaderboard router backed by a
+// memory store holding count entries.
+func memoryLeaderboard(t *testing.T, count int) *mux.Router {
+	dataset, err := loadDataset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedDataset.Store(dataset)
+
+	store := database.NewMemoryStore()
+	for i := 0; i < count; i++ {
+		_, err = store.Create(database.Entry{Name: fmt.Sprintf("player %d", i), Countries: 197 - i, Time: 600})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	leaderboard := &leaderboardHandler{}
+	leaderboard.store.Store(database.LeaderboardStore(store))
+	return leaderboardRouter(leaderboard)
+}
+
+func serve(router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return recorder
+}
+
+func TestGetEntriesPaging(t *testing.T) {
+	router := memoryLeaderboard(t, 25)
+
+	tests := []struct {
+		page    int
+		entries int
+		first   string
+		hasMore bool
+	}{
+		{0, 10, "player 0", true},
+		{1, 10, "player 10", true},
+		{2, 5, "player 20", false},
+		{3, 0, "", false},
+	}
+	for _, test := range tests {
+		response := serve(router, "GET", fmt.Sprintf("/api/leaderboard?page=%d", test.page), "")
+		if response.Code != http.StatusOK {
+			t.Fatalf("page %d: status %d: %s", test.page, response.Code, response.Body)
+		}
+
+		var entries EntriesDto
+		err := json.NewDecoder(response.Body).Decode(&entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries.Entries) != test.entries {
+			t.Errorf("page %d: got %d entries, want %d", test.page, len(entries.Entries), test.entries)
+		}
+		if len(entries.Entries) > 0 && entries.Entries[0].Name != test.first {
+			t.Errorf("page %d: first entry %q, want %q", test.page, entries.Entries[0].Name, test.first)
+		}
+		if entries.HasMore != test.hasMore {
+			t.Errorf("page %d: hasMore %v, want %v", test.page, entries.HasMore, test.hasMore)
+		}
+	}
+}
+
+func TestGetEntriesFullPage(t *testing.T) {
+	router := memoryLeaderboard(t, pageSize)
+
+	var entries EntriesDto
+	response := serve(router, "GET", "/api/leaderboard?page=0", "")
+	err := json.NewDecoder(response.Body).Decode(&entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries.Entries) != pageSize || entries.HasMore {
+		t.Errorf("got %d entries with hasMore %v, want %d without", len(entries.Entries), entries.HasMore, pageSize)
+	}
+}
+
+func TestGetEntriesBadPage(t *testing.T) {
+	router := memoryLeaderboard(t, 1)
+
+	for _, page := range []string{"-1", "first", ""} {
+		response := serve(router, "GET", "/api/leaderboard?page="+page, "")
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("page %q: status %d, want %d", page, response.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestLeaderboardMissingEntry(t *testing.T) {
+	router := memoryLeaderboard(t, 1)
+
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		response := serve(router, method, "/api/leaderboard/2", `{"name": "nobody"}`)
+		if response.Code != http.StatusNotFound {
+			t.Errorf("%s of a missing entry: status %d, want %d", method, response.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestLeaderboardEntry(t *testing.T) {
+	router := memoryLeaderboard(t, 0)
+
+	response := serve(router, "POST", "/api/leaderboard", `{"name": "ash", "country": "nz", "countries": 120, "time": 900}`)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", response.Code, response.Body)
+	}
+	var created database.Entry
+	err := json.NewDecoder(response.Body).Decode(&created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.DatasetVersion != currentDataset().Version.Version {
+		t.Errorf("created with dataset version %q, want %q", created.DatasetVersion, currentDataset().Version.Version)
+	}
+
+	response = serve(router, "GET", fmt.Sprintf("/api/leaderboard/%d", created.ID), "")
+	var got database.Entry
+	err = json.NewDecoder(response.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != created {
+		t.Errorf("got %+v, want %+v", got, created)
+	}
+
+	response = serve(router, "DELETE", fmt.Sprintf("/api/leaderboard/%d", created.ID), "")
+	if response.Code != http.StatusOK {
+		t.Errorf("delete: status %d: %s", response.Code, response.Body)
+	}
+}
+
+func TestUpdateEntryKeepsDatasetVersion(t *testing.T) {
+	router := memoryLeaderboard(t, 0)
+
+	response := serve(router, "POST", "/api/leaderboard", `{"name": "ash", "countries": 120, "time": 900, "datasetVersion": "0.9.0"}`)
+	var created database.Entry
+	err := json.NewDecoder(response.Body).Decode(&created)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response = serve(router, "PUT", fmt.Sprintf("/api/leaderboard/%d", created.ID), `{"name": "ash", "countries": 150, "time": 900}`)
+	var updated database.Entry
+	err = json.NewDecoder(response.Body).Decode(&updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Countries != 150 || updated.DatasetVersion != "0.9.0" {
+		t.Errorf("got %+v, want 150 countries with dataset version 0.9.0", updated)
+	}
+}
+
+func TestLeaderboardUnavailable(t *testing.T) {
+	router := leaderboardRouter(&leaderboardHandler{})
+
+	for _, request := range []struct{ method, target string }{
+		{"GET", "/api/leaderboard?page=0"},
+		{"GET", "/api/leaderboard/1"},
+		{"POST", "/api/leaderboard"},
+		{"PUT", "/api/leaderboard/1"},
+		{"DELETE", "/api/leaderboard/1"},
+	} {
+		response := serve(router, request.method, request.target, "{}")
+		if response.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: status %d, want %d", request.method, request.target, response.Code, http.StatusServiceUnavailable)
+		}
+	}
+}
+
+func TestOpenInBackgroundRetriesUnreachable(t *testing.T) {
+	settings := config.Default()
+	settings.Database.Host = "127.0.0.1"
+	settings.Database.Port = 1
+	settings.Database.ConnectAttempts = 1
+	settings.Database.RetryBackoff = 10 * time.Millisecond
+	settings.Database.MaxRetryBackoff = time.Hour
+
+	leaderboard := &leaderboardHandler{}
+	done := make(chan error, 1)
+	go func() {
+		done <- leaderboard.openInBackground(settings)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("gave up on an unreachable database: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if leaderboard.currentStore() != nil {
+		t.Error("store opened on an unreachable database")
+	}
+}
+
+func TestOpenInBackgroundFailedMigration(t *testing.T) {
+	settings := config.Default()
+	settings.Store = "sqlite"
+	settings.SQLitePath = filepath.Join(t.TempDir(), "missing", "leaderboard.db")
+	settings.Database.RetryBackoff = time.Millisecond
+
+	leaderboard := &leaderboardHandler{}
+	done := make(chan error, 1)
+	go func() {
+		done <- leaderboard.openInBackground(settings)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("opened a database in a missing directory")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("kept retrying a failed migration")
+	}
+}
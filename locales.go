@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultLocale = "en"
+
+// Locale is the data file of country names and aliases for one language.
+type Locale struct {
+	Names   map[string]string `json:"names"`
+	Aliases []Alias           `json:"aliases"`
+}
+
+// LocalizedNames holds the names accepted for each country in one language.
+type LocalizedNames struct {
+	Countries    []string
+	CountriesMap map[string]string
+	Canonical    map[string]string // normalized name to canonical key
+	Aliases      map[string]string // normalized alias to canonical key
+}
+
+func newLocalizedNames() *LocalizedNames {
+	return &LocalizedNames{
+		CountriesMap: make(map[string]string),
+		Canonical:    make(map[string]string),
+		Aliases:      make(map[string]string),
+	}
+}
+
+// localizeNames builds the names for a locale, falling back to the English key of any country it lacks.
+func localizeNames(records []Country, locale Locale) *LocalizedNames {
+	names := newLocalizedNames()
+	mapNames := make(map[string]string)
+	for _, country := range records {
+		if !country.Accepted {
+			continue
+		}
+		mapNames[country.Key] = country.MapName
+
+		name, ok := locale.Names[country.Key]
+		if !ok {
+			name = country.Key
+		}
+		names.Countries = append(names.Countries, name)
+		names.CountriesMap[name] = country.MapName
+		names.Canonical[normalizeName(name)] = country.Key
+	}
+
+	for _, alias := range locale.Aliases {
+		names.CountriesMap[alias.Name] = mapNames[alias.Country]
+		names.Aliases[normalizeName(alias.Name)] = alias.Country
+	}
+	return names
+}
+
+// localized gets the names for a locale, or the English names when the locale is not supported.
+func (dataset *Dataset) localized(locale string) *LocalizedNames {
+	names, ok := dataset.Locales[locale]
+	if !ok {
+		return dataset.Locales[defaultLocale]
+	}
+	return names
+}
+
+// localizedName gets the name of a country or territory in a locale, falling back to its English key.
+func (dataset *Dataset) localizedName(locale, key string) string {
+	if name, ok := dataset.Translations[locale].Names[key]; ok {
+		return name
+	}
+	return key
+}
+
+// acceptedNames lists every name and alias accepted in a locale, names first.
+func (dataset *Dataset) acceptedNames(locale string) []Alias {
+	var names []Alias
+	for i, name := range dataset.localized(locale).Countries {
+		names = append(names, Alias{Name: name, Country: dataset.Countries[i]})
+	}
+
+	aliases := dataset.Aliases
+	if locale != defaultLocale {
+		aliases = dataset.Translations[locale].Aliases
+	}
+	return append(names, aliases...)
+}
+
+// negotiateLocale picks the supported locale for a request from its lang parameter or Accept-Language header.
+func negotiateLocale(request *http.Request, dataset *Dataset) string {
+	lang := strings.ToLower(request.URL.Query().Get("lang"))
+	if _, ok := dataset.Locales[lang]; ok {
+		return lang
+	}
+
+	type preference struct {
+		locale  string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(request.Header.Get("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err == nil {
+					quality = parsed
+				}
+			}
+		}
+		tag := strings.ToLower(strings.SplitN(fields[0], "-", 2)[0])
+		preferences = append(preferences, preference{tag, quality})
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	for _, preference := range preferences {
+		if _, ok := dataset.Locales[preference.locale]; ok && preference.quality > 0 {
+			return preference.locale
+		}
+	}
+	return defaultLocale
+}
+
+func setLocaleHeaders(writer http.ResponseWriter, locale string) {
+	writer.Header().Set("Content-Language", locale)
+	writer.Header().Add("Vary", "Accept-Language")
+}
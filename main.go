@@ -2,41 +2,198 @@ package main
 
 import (
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"time"
 
+	"github.com/ashmidgley/countries-of-the-world-api/config"
 	"github.com/ashmidgley/countries-of-the-world-api/database"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
 func main() {
-	var err error
-	database.DBConnection, err = sql.Open("postgres", database.GetConnectionString())
+	validateOnly := flag.Bool("validate", false, "validate the country datasets and exit")
+	ignoreValidation := flag.Bool("ignore-validation", false, "start even if the country datasets fail validation")
+	settings, args, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		panic(err)
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		runMigrate(settings, args[1:])
+		return
 	}
 
-	err = database.DBConnection.Ping()
+	dataset, err := loadDataset(settings.DataDir)
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println("Successfully connected to database!")
+	problems := validateDataset(dataset)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if *validateOnly {
+		if hasErrors(problems) {
+			os.Exit(1)
+		}
+		return
+	}
+	if hasErrors(problems) && !*ignoreValidation {
+		fmt.Println("Country datasets failed validation, refusing to start.")
+		os.Exit(1)
+	}
+	loadedDataset.Store(dataset)
+
+	loader := &datasetLoader{dir: settings.DataDir, adminToken: settings.AdminToken}
+	go loader.reloadOnSignal()
+	if settings.DataDir != "" && settings.WatchInterval > 0 {
+		go loader.watch(settings.WatchInterval)
+	}
+
+	leaderboard := &leaderboardHandler{}
+	if settings.Degraded {
+		go func() {
+			err := leaderboard.openInBackground(settings)
+			if err != nil {
+				fmt.Printf("Leaderboard unavailable: %v\n", err)
+				os.Exit(1)
+			}
+		}()
+	} else {
+		err = leaderboard.open(settings)
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	router := mux.NewRouter().StrictSlash(true)
 
-	router.HandleFunc("/api/leaderboard", GetEntries).Methods("GET")
-	router.HandleFunc("/api/leaderboard/{id}", GetEntry).Methods("GET")
-	router.HandleFunc("/api/leaderboard", CreateEntry).Methods("POST")
-	router.HandleFunc("/api/leaderboard/{id}", UpdateEntry).Methods("PUT")
-	router.HandleFunc("/api/leaderboard/{id}", DeleteEntry).Methods("DELETE")
+	router.HandleFunc("/api/leaderboard", leaderboard.available(leaderboard.GetEntries)).Methods("GET")
+	router.HandleFunc("/api/leaderboard/{id}", leaderboard.available(leaderboard.GetEntry)).Methods("GET")
+	router.HandleFunc("/api/leaderboard", leaderboard.available(leaderboard.CreateEntry)).Methods("POST")
+	router.HandleFunc("/api/leaderboard/{id}", leaderboard.available(leaderboard.UpdateEntry)).Methods("PUT")
+	router.HandleFunc("/api/leaderboard/{id}", leaderboard.available(leaderboard.DeleteEntry)).Methods("DELETE")
 	router.HandleFunc("/api/countries", GetCountries).Methods("GET")
 	router.HandleFunc("/api/countries/alternatives", GetAlternativeNamings).Methods("GET")
+	router.HandleFunc("/api/countries/aliases", GetAliases).Methods("GET")
 	router.HandleFunc("/api/countries/prefixes", GetPrefixes).Methods("GET")
+	router.HandleFunc("/api/countries/prefixes/conflicts", GetPrefixConflicts).Methods("GET")
 	router.HandleFunc("/api/countries/map", GetCountriesMap).Methods("GET")
+	router.HandleFunc("/api/countries/normalize", NormalizeName).Methods("GET")
+	router.HandleFunc("/api/countries/resolve", ResolveGuess).Methods("POST")
+	router.HandleFunc("/api/countries/resolve/batch", ResolveGuesses).Methods("POST")
+	router.HandleFunc("/api/countries/path", GetPath).Methods("GET")
+	router.HandleFunc("/api/countries/version", GetDatasetVersion).Methods("GET")
+	router.HandleFunc("/api/countries/{code}", GetCountry).Methods("GET")
+	router.HandleFunc("/api/countries/{code}/geo", GetGeo).Methods("GET")
+	router.HandleFunc("/api/countries/{code}/neighbors", GetNeighbors).Methods("GET")
 	router.HandleFunc("/api/codes", GetCodes).Methods("GET")
+	router.HandleFunc("/api/codes/{code}", GetCountry).Methods("GET")
+	router.HandleFunc("/api/codes/{code}/countries", GetCodeCountries).Methods("GET")
+	router.HandleFunc("/api/capitals", GetCapitals).Methods("GET")
+	router.HandleFunc("/api/capitals/resolve", ResolveCapital).Methods("POST")
+	router.HandleFunc("/api/map.svg", GetWorldMap).Methods("GET")
+	router.HandleFunc("/api/flags/{code}.svg", GetFlag).Methods("GET")
+	router.HandleFunc("/api/regions", GetRegions).Methods("GET")
+	router.HandleFunc("/api/regions/{region}/countries", GetRegionCountries).Methods("GET")
+	router.HandleFunc("/api/admin/reload", loader.ReloadData).Methods("POST")
+
+	handler := cors.New(cors.Options{AllowedOrigins: settings.CORS.AllowedOrigins}).Handler(router)
+	http.ListenAndServe(fmt.Sprintf(":%d", settings.Port), handler)
+}
+
+// openStore opens the configured leaderboard store, first applying any pending migrations
+// unless disabled.
+func openStore(settings config.Config) (database.LeaderboardStore, error) {
+	if settings.Store == "memory" {
+		fmt.Println("Keeping the leaderboard in memory.")
+		return database.NewMemoryStore(), nil
+	}
+
+	db, err := openDatabase(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Migrate {
+		applied, err := database.MigrateUp(db, settings.Store)
+		for _, migration := range applied {
+			fmt.Printf("Applied migration %d %s.\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return database.NewSQLStore(db), nil
+}
+
+// openDatabase connects to the postgres or sqlite database holding the leaderboard.
+func openDatabase(settings config.Config) (*sql.DB, error) {
+	switch settings.Store {
+	case "postgres":
+		db, err := sql.Open("postgres", settings.Database.ConnectionString())
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(settings.Database.MaxOpenConns)
+		db.SetMaxIdleConns(settings.Database.MaxIdleConns)
+		db.SetConnMaxLifetime(settings.Database.ConnMaxLifetime)
+
+		err = ping(db, settings.Database)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		fmt.Println("Successfully connected to database!")
+		return db, nil
+	case "sqlite":
+		db, err := database.OpenSQLite(settings.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Keeping the leaderboard in %s.\n", settings.SQLitePath)
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown leaderboard store %s", settings.Store)
+	}
+}
+
+// errUnreachable is returned when the database does not answer within the configured attempts.
+var errUnreachable = errors.New("database unreachable")
+
+// ping waits for the database to answer, retrying with a doubling backoff until the configured
+// number of attempts have failed.
+func ping(db *sql.DB, settings config.Database) error {
+	backoff := settings.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+		if settings.ConnectAttempts > 0 && attempt >= settings.ConnectAttempts {
+			return fmt.Errorf("%w after %d attempts: %v", errUnreachable, attempt, err)
+		}
 
-	handler := cors.Default().Handler(router)
-	http.ListenAndServe(":8080", handler)
+		fmt.Printf("Database unreachable, retrying in %v: %v\n", backoff, err)
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff, settings)
+	}
+}
+
+// nextBackoff doubles a wait between attempts to reach the database, up to the configured longest.
+func nextBackoff(backoff time.Duration, settings config.Database) time.Duration {
+	backoff *= 2
+	if backoff > settings.MaxRetryBackoff {
+		return settings.MaxRetryBackoff
+	}
+	return backoff
 }
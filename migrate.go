@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ashmidgley/countries-of-the-world-api/config"
+	"github.com/ashmidgley/countries-of-the-world-api/database"
+)
+
+// runMigrate runs the migrate subcommand: "migrate up" applies every pending migration,
+// "migrate down [n]" reverts the last n applied migrations, one by default, and
+// "migrate status" lists the migrations and whether each is applied. Flags may come before or
+// after the subcommand.
+func runMigrate(settings config.Config, args []string) {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	db, err := openDatabase(settings)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	var migrations []database.Migration
+	switch command {
+	case "up":
+		migrations, err = database.MigrateUp(db, settings.Store)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Printf("migrate down takes a positive number of migrations, not %s\n", args[1])
+				os.Exit(1)
+			}
+		}
+		migrations, err = database.MigrateDown(db, settings.Store, steps)
+	case "status":
+		migrations, err = database.Migrations(db, settings.Store)
+	default:
+		fmt.Printf("unknown migrate command %s, expected up, down or status\n", command)
+		os.Exit(1)
+	}
+
+	for _, migration := range migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%04d %s: %s\n", migration.Version, migration.Name, state)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
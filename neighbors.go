@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Neighbor is a country reached by crossing a land border.
+type Neighbor struct {
+	Key     string `json:"key"`
+	MapName string `json:"mapName"`
+	Code    string `json:"code"`
+}
+
+// Path is the shortest chain of bordering countries between two countries.
+type Path struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Crossings int        `json:"crossings"`
+	Countries []Neighbor `json:"countries"`
+}
+
+func (dataset *Dataset) neighbor(code string) Neighbor {
+	country, _ := dataset.findCountry(code)
+	return Neighbor{country.Key, country.MapName, country.Alpha2}
+}
+
+// shortestPath finds the fewest border crossings between two alpha-2 codes with a breadth first
+// search, returning the codes along the way or nil when there is no land route.
+func (dataset *Dataset) shortestPath(from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		code := queue[0]
+		queue = queue[1:]
+		if code == to {
+			var path []string
+			for ; code != ""; code = previous[code] {
+				path = append([]string{code}, path...)
+			}
+			return path
+		}
+
+		for _, next := range dataset.Borders[code] {
+			if _, ok := previous[next]; !ok {
+				previous[next] = code
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// GetNeighbors gets the countries sharing a land border with the country with an ISO-3166 code.
+func GetNeighbors(writer http.ResponseWriter, request *http.Request) {
+	dataset := currentDataset()
+	code := mux.Vars(request)["code"]
+	country, ok := dataset.findCountry(code)
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no country with code %s\n", code)
+		return
+	}
+
+	neighbors := []Neighbor{}
+	for _, code := range dataset.Borders[country.Alpha2] {
+		neighbors = append(neighbors, dataset.neighbor(code))
+	}
+	json.NewEncoder(writer).Encode(neighbors)
+}
+
+// GetPath gets the fewest border crossings between the countries with the from and to ISO-3166 codes.
+func GetPath(writer http.ResponseWriter, request *http.Request) {
+	dataset := currentDataset()
+	var ends [2]Country
+	for i, name := range []string{"from", "to"} {
+		code := request.URL.Query().Get(name)
+		if code == "" {
+			writer.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(writer, "missing %s code\n", name)
+			return
+		}
+
+		country, ok := dataset.findCountry(code)
+		if !ok {
+			writer.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(writer, "no country with code %s\n", code)
+			return
+		}
+		ends[i] = country
+	}
+
+	codes := dataset.shortestPath(ends[0].Alpha2, ends[1].Alpha2)
+	if codes == nil {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no land route from %s to %s\n", ends[0].Key, ends[1].Key)
+		return
+	}
+
+	path := Path{From: ends[0].Key, To: ends[1].Key, Crossings: len(codes) - 1}
+	for _, code := range codes {
+		path.Countries = append(path.Countries, dataset.neighbor(code))
+	}
+	json.NewEncoder(writer).Encode(path)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// borderDataset is a fixture of a chain of bordering countries, fr-de-pl, with de also bordering
+// ch, and an island, is, with no land borders.
+func borderDataset() *Dataset {
+	dataset := fixtureDataset(
+		fixtureCountry("france", "France", "fr", "FRA", "250"),
+		fixtureCountry("germany", "Germany", "de", "DEU", "276"),
+		fixtureCountry("poland", "Poland", "pl", "POL", "616"),
+		fixtureCountry("switzerland", "Switzerland", "ch", "CHE", "756"),
+		fixtureCountry("iceland", "Iceland", "is", "ISL", "352"),
+	)
+	dataset.Borders = map[string][]string{
+		"fr": {"ch", "de"},
+		"de": {"ch", "fr", "pl"},
+		"pl": {"de"},
+		"ch": {"de", "fr"},
+		"is": {},
+	}
+	return dataset
+}
+
+func TestShortestPath(t *testing.T) {
+	dataset := borderDataset()
+
+	tests := []struct {
+		from, to string
+		path     []string
+	}{
+		{"fr", "de", []string{"fr", "de"}},
+		{"fr", "pl", []string{"fr", "de", "pl"}},
+		{"ch", "pl", []string{"ch", "de", "pl"}},
+		{"pl", "ch", []string{"pl", "de", "ch"}},
+		{"fr", "fr", []string{"fr"}},
+		{"is", "is", []string{"is"}},
+		{"fr", "is", nil},
+		{"is", "pl", nil},
+	}
+	for _, test := range tests {
+		path := dataset.shortestPath(test.from, test.to)
+		if !reflect.DeepEqual(path, test.path) {
+			t.Errorf("shortestPath(%q, %q) = %v, want %v", test.from, test.to, path, test.path)
+		}
+	}
+}
+
+func TestGetPathStatus(t *testing.T) {
+	loadedDataset.Store(borderDataset())
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/countries/path", GetPath).Methods("GET")
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"?from=FRA&to=pl", http.StatusOK},
+		{"?from=fr&to=fr", http.StatusOK},
+		{"?from=fr&to=is", http.StatusNotFound},
+		{"?from=fr&to=zz", http.StatusNotFound},
+		{"?from=fr", http.StatusBadRequest},
+		{"?to=fr", http.StatusBadRequest},
+		{"?from=&to=fr", http.StatusBadRequest},
+		{"", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		response := serve(router, "GET", "/api/countries/path"+test.query, "")
+		if response.Code != test.status {
+			t.Errorf("%q: status %d, want %d: %s", test.query, response.Code, test.status, response.Body)
+		}
+	}
+}
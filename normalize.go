@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization shows how a submission is normalized before it is looked up.
+type Normalization struct {
+	Input      string `json:"input"`
+	Normalized string `json:"normalized"`
+}
+
+var punctuationReplacer = strings.NewReplacer(
+	"’", "'", "‘", "'", "`", "'", "´", "'", "ʼ", "'",
+	"&", " and ",
+	"ß", "ss",
+	"-", " ", "–", " ", ",", " ", "(", " ", ")", " ", "/", " ",
+)
+
+var abbreviations = map[string]string{
+	"st":   "saint",
+	"st.":  "saint",
+	"ste":  "sainte",
+	"ste.": "sainte",
+}
+
+// normalizeName folds a country name so that variants in case, diacritics, apostrophes,
+// punctuation, "&" and "st." all compare equal.
+func normalizeName(name string) string {
+	decomposed := norm.NFKD.String(strings.ToLower(name))
+	var folded strings.Builder
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			folded.WriteRune(r)
+		}
+	}
+
+	words := strings.Fields(punctuationReplacer.Replace(folded.String()))
+	for i, word := range words {
+		if expanded, ok := abbreviations[word]; ok {
+			words[i] = expanded
+			continue
+		}
+		words[i] = strings.ReplaceAll(word, ".", "")
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeName shows the normalized form of a submission.
+func NormalizeName(writer http.ResponseWriter, request *http.Request) {
+	input := request.URL.Query().Get("q")
+	json.NewEncoder(writer).Encode(Normalization{input, normalizeName(input)})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// PrefixConflict is an accepted name that is also the start of another country's name, so a
+// player typing the longer name passes through a correct answer for a different country.
+type PrefixConflict struct {
+	Prefix   string `json:"prefix"`
+	Country  string `json:"country"`
+	Name     string `json:"name"`
+	PrefixOf string `json:"prefixOf"`
+	New      bool   `json:"new"`
+}
+
+// findPrefixConflicts finds every name that is the start of a name of a different country,
+// comparing normalized forms. Conflicts not in known are flagged as new.
+func findPrefixConflicts(names []Alias, mapNames map[string]string, known map[string]string) []PrefixConflict {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = normalizeName(name.Name)
+	}
+
+	var conflicts []PrefixConflict
+	for i, prefix := range names {
+		for j, name := range names {
+			if prefix.Country == name.Country || len(normalized[j]) <= len(normalized[i]) {
+				continue
+			}
+			if !strings.HasPrefix(normalized[j], normalized[i]) {
+				continue
+			}
+
+			_, ok := known[prefix.Name]
+			conflicts = append(conflicts, PrefixConflict{
+				Prefix:   prefix.Name,
+				Country:  prefix.Country,
+				Name:     name.Name,
+				PrefixOf: mapNames[name.Country],
+				New:      !ok,
+			})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Prefix != conflicts[j].Prefix {
+			return conflicts[i].Prefix < conflicts[j].Prefix
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	return conflicts
+}
+
+// prefixTable maps each conflicting prefix to the SVG map name of the first country it is the start of.
+func prefixTable(conflicts []PrefixConflict) map[string]string {
+	prefixes := make(map[string]string)
+	for _, conflict := range conflicts {
+		if _, ok := prefixes[conflict.Prefix]; !ok {
+			prefixes[conflict.Prefix] = conflict.PrefixOf
+		}
+	}
+	return prefixes
+}
+
+// GetPrefixConflicts gets the prefix conflicts found in the accepted names, flagging those not yet listed in prefixes.json.
+func GetPrefixConflicts(writer http.ResponseWriter, request *http.Request) {
+	json.NewEncoder(writer).Encode(currentDataset().PrefixConflicts)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	regionContinent = "continent"
+	regionSubregion = "subregion"
+)
+
+// Region is a continent or UN M49 subregion that countries are grouped by.
+type Region struct {
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	Type      string `json:"type"`
+	M49       string `json:"m49,omitempty"`
+	Countries int    `json:"countries"`
+}
+
+// RegionCountry is a country listed for a region.
+type RegionCountry struct {
+	Key     string `json:"key"`
+	MapName string `json:"mapName"`
+	Code    string `json:"code"`
+}
+
+func slugify(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// contains reports whether a country is in the region.
+func (region Region) contains(country Country) bool {
+	if region.Type == regionContinent {
+		return country.Continent == region.Name
+	}
+	return country.Subregion == region.Name
+}
+
+// countRegions fills in the slug and number of accepted countries of each region. A subregion
+// named after a continent, such as South America, has its type appended to keep its slug unique.
+func countRegions(regions []Region, records []Country) {
+	slugs := make(map[string]bool)
+	for i := range regions {
+		regions[i].Slug = slugify(regions[i].Name)
+		if slugs[regions[i].Slug] {
+			regions[i].Slug += "-" + regions[i].Type
+		}
+		slugs[regions[i].Slug] = true
+
+		regions[i].Countries = 0
+		for _, country := range records {
+			if country.Accepted && regions[i].contains(country) {
+				regions[i].Countries++
+			}
+		}
+	}
+}
+
+// findRegion finds a region by its slug, name or M49 code, preferring continents where a name is shared.
+func (dataset *Dataset) findRegion(id string) (Region, bool) {
+	for _, region := range dataset.Regions {
+		if region.Slug == strings.ToLower(id) || strings.EqualFold(region.Name, id) || (region.M49 != "" && region.M49 == id) {
+			return region, true
+		}
+	}
+	return Region{}, false
+}
+
+// GetRegions gets the list of continents and subregions.
+func GetRegions(writer http.ResponseWriter, request *http.Request) {
+	json.NewEncoder(writer).Encode(currentDataset().Regions)
+}
+
+// GetRegionCountries gets the accepted countries in a region, or those with the given statuses.
+func GetRegionCountries(writer http.ResponseWriter, request *http.Request) {
+	dataset := currentDataset()
+	id := mux.Vars(request)["region"]
+	region, ok := dataset.findRegion(id)
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no region %s\n", id)
+		return
+	}
+
+	records, err := dataset.recordsWithStatus(request.URL.Query().Get("status"))
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	countries := []RegionCountry{}
+	for _, country := range records {
+		if region.contains(country) {
+			countries = append(countries, RegionCountry{country.Key, country.MapName, country.Alpha2})
+		}
+	}
+	json.NewEncoder(writer).Encode(countries)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// errInvalidDataset is returned by a reload whose data failed validation.
+var errInvalidDataset = errors.New("country data failed validation")
+
+// ReloadResult reports the outcome of reloading the country data.
+type ReloadResult struct {
+	Reloaded bool      `json:"reloaded"`
+	Error    string    `json:"error,omitempty"`
+	Problems []Problem `json:"problems"`
+}
+
+// datasetLoader loads the country data and swaps it in for the one being served.
+type datasetLoader struct {
+	dir        string
+	adminToken string
+	mutex      sync.Mutex
+}
+
+// reload loads and validates the country data, keeping the current dataset live if either step fails.
+func (loader *datasetLoader) reload() ([]Problem, error) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	dataset, err := loadDataset(loader.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := validateDataset(dataset)
+	if hasErrors(problems) {
+		return problems, errInvalidDataset
+	}
+
+	loadedDataset.Store(dataset)
+	return problems, nil
+}
+
+func (loader *datasetLoader) reloadAndLog(reason string) {
+	problems, err := loader.reload()
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if err != nil {
+		fmt.Printf("Reloading country data on %s failed, keeping the previous data: %v\n", reason, err)
+		return
+	}
+	fmt.Printf("Reloaded country data on %s.\n", reason)
+}
+
+// reloadOnSignal reloads the country data whenever the process receives SIGHUP.
+func (loader *datasetLoader) reloadOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		loader.reloadAndLog("SIGHUP")
+	}
+}
+
+// watch polls the data directory and reloads the country data when any file in it changes.
+func (loader *datasetLoader) watch(interval time.Duration) {
+	last := loader.lastModified()
+	for range time.Tick(interval) {
+		modified := loader.lastModified()
+		if modified.Equal(last) {
+			continue
+		}
+		last = modified
+		loader.reloadAndLog("change to " + loader.dir)
+	}
+}
+
+func (loader *datasetLoader) lastModified() time.Time {
+	var latest time.Time
+	filepath.Walk(loader.dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// ReloadData reloads the country data from the data files.
+func (loader *datasetLoader) ReloadData(writer http.ResponseWriter, request *http.Request) {
+	if loader.adminToken == "" || request.Header.Get("Authorization") != "Bearer "+loader.adminToken {
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	problems, err := loader.reload()
+	if problems == nil {
+		problems = []Problem{}
+	}
+	result := ReloadResult{Reloaded: err == nil, Problems: problems}
+	switch {
+	case err == errInvalidDataset:
+		writer.WriteHeader(http.StatusUnprocessableEntity)
+		result.Error = err.Error()
+	case err != nil:
+		writer.WriteHeader(http.StatusInternalServerError)
+		result.Error = err.Error()
+	}
+	json.NewEncoder(writer).Encode(result)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	matchCanonical = "canonical"
+	matchAlias     = "alias"
+	matchFuzzy     = "fuzzy"
+	matchUnknown   = "unknown"
+)
+
+// Guess is the request body for resolving a single player submission. Fuzzy opts in to
+// typo-tolerant matching, and MaxDistance optionally lowers the edit distance it allows.
+type Guess struct {
+	Input       string `json:"input"`
+	Fuzzy       bool   `json:"fuzzy"`
+	MaxDistance int    `json:"maxDistance"`
+}
+
+// Guesses is the request body for resolving a batch of player submissions.
+type Guesses struct {
+	Inputs      []string `json:"inputs"`
+	Fuzzy       bool     `json:"fuzzy"`
+	MaxDistance int      `json:"maxDistance"`
+}
+
+// Resolution describes how a player submission maps onto the country datasets.
+type Resolution struct {
+	Input      string      `json:"input"`
+	Match      string      `json:"match"`
+	Country    string      `json:"country,omitempty"`
+	MapName    string      `json:"mapName,omitempty"`
+	Code       string      `json:"code,omitempty"`
+	PrefixOf   string      `json:"prefixOf,omitempty"`
+	Locale     string      `json:"locale,omitempty"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// resolve resolves a raw submission to the canonical country it stands for, matching names
+// in the given locale before falling back to English.
+func (dataset *Dataset) resolve(input, locale string) Resolution {
+	guess := normalizeName(input)
+	resolution := Resolution{Input: input, Match: matchUnknown, PrefixOf: dataset.PrefixIndex[guess]}
+
+	locales := []string{defaultLocale}
+	if locale != defaultLocale {
+		locales = []string{locale, defaultLocale}
+	}
+
+	for _, locale := range locales {
+		names, ok := dataset.Locales[locale]
+		if !ok {
+			continue
+		}
+
+		match := matchCanonical
+		key, ok := names.Canonical[guess]
+		if !ok {
+			match = matchAlias
+			key, ok = names.Aliases[guess]
+		}
+		if !ok {
+			continue
+		}
+
+		return dataset.matched(resolution, match, key, locale)
+	}
+
+	return resolution
+}
+
+func (dataset *Dataset) matched(resolution Resolution, match, key, locale string) Resolution {
+	resolution.Match = match
+	resolution.Country = key
+	resolution.MapName = dataset.CountriesMap[key]
+	resolution.Code = dataset.Codes[resolution.MapName]
+	resolution.Locale = locale
+	return resolution
+}
+
+// ResolveGuess resolves a player submission to its canonical country.
+func ResolveGuess(writer http.ResponseWriter, request *http.Request) {
+	requestBody, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	var guess Guess
+	err = json.Unmarshal(requestBody, &guess)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	dataset := currentDataset()
+	locale := negotiateLocale(request, dataset)
+	if guess.Fuzzy {
+		json.NewEncoder(writer).Encode(dataset.resolveFuzzy(guess.Input, locale, guess.MaxDistance))
+		return
+	}
+	json.NewEncoder(writer).Encode(dataset.resolve(guess.Input, locale))
+}
+
+// ResolveGuesses resolves a batch of player submissions to their canonical countries.
+func ResolveGuesses(writer http.ResponseWriter, request *http.Request) {
+	requestBody, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	var guesses Guesses
+	err = json.Unmarshal(requestBody, &guesses)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(writer, "%v\n", err)
+		return
+	}
+
+	dataset := currentDataset()
+	locale := negotiateLocale(request, dataset)
+	resolutions := make([]Resolution, 0, len(guesses.Inputs))
+	for _, input := range guesses.Inputs {
+		if guesses.Fuzzy {
+			resolutions = append(resolutions, dataset.resolveFuzzy(input, locale, guesses.MaxDistance))
+			continue
+		}
+		resolutions = append(resolutions, dataset.resolve(input, locale))
+	}
+	json.NewEncoder(writer).Encode(resolutions)
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// Problem is an inconsistency found in the country datasets.
+type Problem struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+}
+
+func (problem Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", problem.Severity, problem.Kind, problem.Message)
+}
+
+func hasErrors(problems []Problem) bool {
+	for _, problem := range problems {
+		if problem.Severity == severityError {
+			return true
+		}
+	}
+	return false
+}
+
+// reporter records a problem found by a check.
+type reporter func(severity, kind, format string, args ...interface{})
+
+// validateDataset checks that the country records and the tables derived from them agree with each other.
+func validateDataset(dataset *Dataset) []Problem {
+	var problems []Problem
+	report := func(severity, kind, format string, args ...interface{}) {
+		problems = append(problems, Problem{severity, kind, fmt.Sprintf(format, args...)})
+	}
+
+	for _, check := range []func(*Dataset, reporter){
+		checkNames,
+		checkCodes,
+		checkLocales,
+		checkRegions,
+		checkCapitals,
+		checkFlags,
+		checkBorders,
+		checkGeo,
+		checkVersion,
+	} {
+		check(dataset, report)
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Severity != problems[j].Severity {
+			return problems[i].Severity < problems[j].Severity
+		}
+		if problems[i].Kind != problems[j].Kind {
+			return problems[i].Kind < problems[j].Kind
+		}
+		return problems[i].Message < problems[j].Message
+	})
+	return problems
+}
+
+// checkNames checks that keys, aliases and map names are lowercase where they should be and
+// unique, and that the legacy tables built from them agree with each other, the map and prefixes.json.
+func checkNames(dataset *Dataset, report reporter) {
+	names := make(map[string]string)
+	mapNames := make(map[string]string)
+	for _, country := range dataset.Records {
+		if country.Key != strings.ToLower(country.Key) {
+			report(severityError, "case", "key %q is not lowercase", country.Key)
+		}
+		if !isStatus(country.Status) {
+			report(severityError, "status", "%q has unknown status %q", country.Key, country.Status)
+		}
+		if other, ok := names[country.Key]; ok {
+			report(severityError, "duplicate", "%q is used by both %q and %q", country.Key, other, country.Key)
+		}
+		names[country.Key] = country.Key
+
+		for _, alias := range country.Aliases {
+			if alias.Name != strings.ToLower(alias.Name) {
+				report(severityError, "case", "alias %q of %q is not lowercase", alias.Name, country.Key)
+			}
+			if other, ok := names[alias.Name]; ok {
+				report(severityError, "duplicate", "%q is used by both %q and %q", alias.Name, other, country.Key)
+			}
+			names[alias.Name] = country.Key
+		}
+
+		if other, ok := mapNames[country.MapName]; ok {
+			report(severityError, "duplicate", "map name %q is used by both %q and %q", country.MapName, other, country.Key)
+		}
+		mapNames[country.MapName] = country.Key
+	}
+
+	for _, country := range dataset.Countries {
+		if _, ok := dataset.CountriesMap[country]; !ok {
+			report(severityError, "orphan", "country %q has no entry in countriesMap", country)
+		}
+	}
+
+	for name, mapName := range dataset.CountriesMap {
+		if _, ok := dataset.Codes[mapName]; !ok {
+			report(severityError, "orphan", "countriesMap entry %q maps to %q which has no code", name, mapName)
+		}
+		if dataset.WorldMap != nil && !dataset.WorldMap.Paths[mapName] {
+			report(severityError, "map", "countriesMap entry %q maps to %q which has no path in map.svg", name, mapName)
+		}
+	}
+
+	if dataset.WorldMap == nil {
+		report(severityWarning, "map", "no map.svg is bundled, so /api/map.svg answers 404")
+	}
+
+	for _, alias := range dataset.Aliases {
+		if _, ok := dataset.CountriesMap[alias.Country]; !ok {
+			report(severityError, "orphan", "alias %q refers to %q which is not an accepted country", alias.Name, alias.Country)
+		}
+	}
+
+	for _, conflict := range dataset.PrefixConflicts {
+		if conflict.New {
+			report(severityWarning, "prefix", "new conflict: %q of %q is the start of %q", conflict.Prefix, conflict.Country, conflict.Name)
+		}
+	}
+	for prefix := range dataset.KnownPrefixes {
+		if _, ok := dataset.Prefixes[prefix]; !ok {
+			report(severityWarning, "prefix", "%q in prefixes.json no longer conflicts with another country", prefix)
+		}
+	}
+}
+
+// checkCodes checks the case and uniqueness of the ISO-3166 codes, warning about alpha-2 codes
+// shared by several records.
+func checkCodes(dataset *Dataset, report reporter) {
+	alpha3Codes := make(map[string]string)
+	numericCodes := make(map[string]string)
+	sharedCodes := make(map[string][]string)
+	for _, country := range dataset.Records {
+		if country.Alpha2 != strings.ToLower(country.Alpha2) {
+			report(severityError, "case", "alpha-2 code %q of %q is not lowercase", country.Alpha2, country.Key)
+		}
+		if country.Alpha3 != strings.ToUpper(country.Alpha3) {
+			report(severityError, "case", "alpha-3 code %q of %q is not uppercase", country.Alpha3, country.Key)
+		}
+
+		if other, ok := alpha3Codes[country.Alpha3]; ok && country.Alpha3 != "" {
+			report(severityError, "duplicate", "alpha-3 code %q is used by both %q and %q", country.Alpha3, other, country.Key)
+		}
+		alpha3Codes[country.Alpha3] = country.Key
+
+		if other, ok := numericCodes[country.Numeric]; ok && country.Numeric != "" {
+			report(severityError, "duplicate", "numeric code %q is used by both %q and %q", country.Numeric, other, country.Key)
+		}
+		numericCodes[country.Numeric] = country.Key
+
+		sharedCodes[country.Alpha2] = append(sharedCodes[country.Alpha2], country.MapName)
+	}
+
+	for code, mapNames := range sharedCodes {
+		if len(mapNames) > 1 {
+			report(severityWarning, "shared code", "%q is shared by %s", code, strings.Join(mapNames, ", "))
+		}
+	}
+}
+
+// checkLocales checks the translated names and aliases, and that no two names of a locale
+// normalize to the same spelling.
+func checkLocales(dataset *Dataset, report reporter) {
+	for _, locale := range sortedKeys(dataset.Translations) {
+		translation := dataset.Translations[locale]
+		localized := make(map[string]string)
+		for key, name := range translation.Names {
+			if _, ok := dataset.CountriesMap[key]; !ok {
+				report(severityError, "orphan", "%s name %q refers to %q which is not an accepted country", locale, name, key)
+			}
+			if name != strings.ToLower(name) {
+				report(severityError, "case", "%s name %q of %q is not lowercase", locale, name, key)
+			}
+			if other, ok := localized[name]; ok {
+				report(severityError, "duplicate", "%s name %q is used by both %q and %q", locale, name, other, key)
+			}
+			localized[name] = key
+		}
+
+		for _, alias := range translation.Aliases {
+			if _, ok := dataset.CountriesMap[alias.Country]; !ok {
+				report(severityError, "orphan", "%s alias %q refers to %q which is not an accepted country", locale, alias.Name, alias.Country)
+			}
+			if alias.Name != strings.ToLower(alias.Name) {
+				report(severityError, "case", "%s alias %q of %q is not lowercase", locale, alias.Name, alias.Country)
+			}
+			if other, ok := localized[alias.Name]; ok {
+				report(severityError, "duplicate", "%s alias %q is used by both %q and %q", locale, alias.Name, other, alias.Country)
+			}
+			localized[alias.Name] = alias.Country
+		}
+	}
+
+	for _, locale := range append([]string{defaultLocale}, sortedKeys(dataset.Translations)...) {
+		normalized := make(map[string]Alias)
+		for _, name := range dataset.acceptedNames(locale) {
+			folded := normalizeName(name.Name)
+			other, ok := normalized[folded]
+			switch {
+			case !ok:
+				normalized[folded] = name
+			case other.Country != name.Country:
+				report(severityError, "normalization", "%s names %q of %q and %q of %q both normalize to %q", locale, other.Name, other.Country, name.Name, name.Country, folded)
+			case name.Reason == "diacritics" || other.Reason == "diacritics":
+				// Spellings with diacritics are redundant for matching but stay in the legacy
+				// alternatives and countries map tables, which clients may look them up in.
+			default:
+				report(severityWarning, "normalization", "%s name %q of %q is redundant with %q", locale, name.Name, name.Country, other.Name)
+			}
+		}
+	}
+}
+
+// checkRegions checks that every record is in a continent and subregion of regions.json.
+func checkRegions(dataset *Dataset, report reporter) {
+	regions := make(map[string]bool)
+	for _, region := range dataset.Regions {
+		regions[region.Type+":"+region.Name] = true
+	}
+	for _, country := range dataset.Records {
+		if !regions[regionContinent+":"+country.Continent] {
+			report(severityError, "orphan", "%q is in %q which is not a continent in regions.json", country.Key, country.Continent)
+		}
+		if !regions[regionSubregion+":"+country.Subregion] {
+			report(severityError, "orphan", "%q is in %q which is not a subregion in regions.json", country.Key, country.Subregion)
+		}
+	}
+}
+
+// checkCapitals checks that capitals belong to accepted countries and that their aliases are
+// lowercase and not redundant.
+func checkCapitals(dataset *Dataset, report reporter) {
+	for _, country := range dataset.Countries {
+		if _, ok := dataset.Capitals[country]; !ok {
+			report(severityWarning, "capital", "country %q has no capital in capitals.json", country)
+		}
+	}
+	for country, capital := range dataset.Capitals {
+		if _, ok := dataset.CountriesMap[country]; !ok {
+			report(severityError, "orphan", "capital %q refers to %q which is not an accepted country", capital.Name, country)
+		}
+
+		capitalNames := map[string]string{normalizeName(capital.Name): capital.Name}
+		for _, alias := range capital.Aliases {
+			if alias != strings.ToLower(alias) {
+				report(severityError, "case", "capital alias %q of %q is not lowercase", alias, country)
+			}
+			if other, ok := capitalNames[normalizeName(alias)]; ok {
+				report(severityWarning, "normalization", "capital alias %q of %q is redundant with %q", alias, country, other)
+			}
+			capitalNames[normalizeName(alias)] = alias
+		}
+	}
+}
+
+// checkFlags checks that every accepted country has a flag, when any are bundled, and that
+// every flag is named by the alpha-2 code of a country.
+func checkFlags(dataset *Dataset, report reporter) {
+	if len(dataset.Flags) == 0 {
+		report(severityWarning, "flag", "no flags are bundled, so /api/flags answers 404")
+	} else {
+		for _, country := range dataset.acceptedRecords() {
+			if _, ok := dataset.Flags[country.Alpha2]; !ok {
+				report(severityError, "flag", "%q has no flag %s.svg", country.Key, country.Alpha2)
+			}
+		}
+	}
+	for code := range dataset.Flags {
+		if country, ok := dataset.findCountry(code); !ok || country.Alpha2 != code {
+			report(severityError, "orphan", "flag %s.svg is not named by the alpha-2 code of a country", code)
+		}
+	}
+}
+
+// checkBorders checks that borders.json covers the accepted countries and that every border
+// is listed from both sides.
+func checkBorders(dataset *Dataset, report reporter) {
+	for _, country := range dataset.acceptedRecords() {
+		if _, ok := dataset.Borders[country.Alpha2]; !ok {
+			report(severityWarning, "border", "%q has no entry in borders.json", country.Key)
+		}
+	}
+	for code, neighbors := range dataset.Borders {
+		if country, ok := dataset.findCountry(code); !ok || country.Alpha2 != code {
+			report(severityError, "orphan", "borders.json entry %q is not the alpha-2 code of a country", code)
+		}
+		for _, neighbor := range neighbors {
+			if !containsString(dataset.Borders[neighbor], code) {
+				report(severityError, "border", "%q borders %q but not the other way round", code, neighbor)
+			}
+		}
+	}
+}
+
+// checkGeo checks that every record has an area and a bounding box around its centroid.
+func checkGeo(dataset *Dataset, report reporter) {
+	for _, country := range dataset.Records {
+		geo := country.Geo
+		if geo.Area <= 0 {
+			report(severityError, "geo", "%q has no area", country.Key)
+		}
+		if !geo.Bounds.contains(geo.Latitude, geo.Longitude) {
+			report(severityError, "geo", "centroid of %q is outside its bounding box", country.Key)
+		}
+		if geo.Bounds.MinLatitude > geo.Bounds.MaxLatitude {
+			report(severityError, "geo", "bounding box of %q has its minimum latitude above its maximum", country.Key)
+		}
+		// No country spans more than half the globe, so a wider box has its longitudes the wrong
+		// way round for crossing the antimeridian, or the other way round for not crossing it.
+		if span := geo.Bounds.longitudeSpan(); span > 180 {
+			report(severityError, "geo", "bounding box of %q spans %.1f degrees of longitude", country.Key, span)
+		}
+	}
+}
+
+// checkVersion checks that version.json has a semantic version with a changelog entry.
+func checkVersion(dataset *Dataset, report reporter) {
+	if !semanticVersion.MatchString(dataset.Version.Version) {
+		report(severityError, "version", "%q in version.json is not a semantic version", dataset.Version.Version)
+	}
+	if len(dataset.Version.Changelog) == 0 || dataset.Version.Changelog[0].Version != dataset.Version.Version {
+		report(severityError, "version", "version.json has no changelog entry for %q", dataset.Version.Version)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(translations map[string]Locale) []string {
+	keys := make([]string, 0, len(translations))
+	for key := range translations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
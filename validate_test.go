@@ -0,0 +1,213 @@
+package main
+
+import "testing"
+
+func TestValidateEmbeddedDataset(t *testing.T) {
+	dataset, err := loadDataset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems := validateDataset(dataset)
+	if hasErrors(problems) {
+		t.Errorf("embedded dataset has errors: %v", problems)
+	}
+	for _, problem := range problems {
+		if problem.Kind == "normalization" {
+			t.Errorf("embedded dataset has a redundant name: %v", problem)
+		}
+	}
+}
+
+// fixtureCountry returns an accepted country with consistent codes, region and geo data.
+func fixtureCountry(key, mapName, alpha2, alpha3, numeric string) Country {
+	return Country{
+		Key:       key,
+		Name:      mapName,
+		MapName:   mapName,
+		Alpha2:    alpha2,
+		Alpha3:    alpha3,
+		Numeric:   numeric,
+		Continent: "Europe",
+		Subregion: "Western Europe",
+		Geo: Geo{
+			Latitude:  1,
+			Longitude: 1,
+			Bounds:    Bounds{MinLatitude: 0, MinLongitude: 0, MaxLatitude: 2, MaxLongitude: 2},
+			Area:      1,
+		},
+		Status:   statusMember,
+		Accepted: true,
+	}
+}
+
+// fixtureDataset builds a dataset from records, giving every accepted country a capital, flag,
+// borders entry and map path so that only the records themselves can be at fault.
+func fixtureDataset(records ...Country) *Dataset {
+	dataset := newDataset(records, nil, nil)
+	dataset.Regions = []Region{
+		{Name: "Europe", Type: regionContinent},
+		{Name: "Western Europe", Type: regionSubregion},
+	}
+	dataset.Capitals = make(map[string]Capital)
+	dataset.Flags = make(map[string]Flag)
+	dataset.Borders = make(map[string][]string)
+	dataset.WorldMap = &WorldMap{Paths: make(map[string]bool)}
+	for _, country := range dataset.acceptedRecords() {
+		dataset.Capitals[country.Key] = Capital{Name: country.Key + " city"}
+		dataset.Flags[country.Alpha2] = Flag{}
+		dataset.Borders[country.Alpha2] = []string{}
+		dataset.WorldMap.Paths[country.MapName] = true
+	}
+	dataset.Version = DatasetVersion{"1.0.0", []Change{{Version: "1.0.0"}}}
+	return dataset
+}
+
+func TestValidateFixtureDataset(t *testing.T) {
+	dataset := fixtureDataset(
+		fixtureCountry("france", "France", "fr", "FRA", "250"),
+		fixtureCountry("germany", "Germany", "de", "DEU", "276"),
+	)
+
+	problems := validateDataset(dataset)
+	if len(problems) != 0 {
+		t.Errorf("fixture dataset has problems: %v", problems)
+	}
+}
+
+func TestValidateDatasetProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		dataset func() *Dataset
+		problem Problem
+	}{
+		{
+			name: "orphan capital",
+			dataset: func() *Dataset {
+				dataset := fixtureDataset(fixtureCountry("france", "France", "fr", "FRA", "250"))
+				dataset.Capitals["atlantis"] = Capital{Name: "poseidonis"}
+				return dataset
+			},
+			problem: Problem{severityError, "orphan", `capital "poseidonis" refers to "atlantis" which is not an accepted country`},
+		},
+		{
+			name: "orphan flag",
+			dataset: func() *Dataset {
+				dataset := fixtureDataset(fixtureCountry("france", "France", "fr", "FRA", "250"))
+				dataset.Flags["zz"] = Flag{}
+				return dataset
+			},
+			problem: Problem{severityError, "orphan", "flag zz.svg is not named by the alpha-2 code of a country"},
+		},
+		{
+			name: "missing flag",
+			dataset: func() *Dataset {
+				dataset := fixtureDataset(
+					fixtureCountry("france", "France", "fr", "FRA", "250"),
+					fixtureCountry("germany", "Germany", "de", "DEU", "276"),
+				)
+				delete(dataset.Flags, "de")
+				return dataset
+			},
+			problem: Problem{severityError, "flag", `"germany" has no flag de.svg`},
+		},
+		{
+			name: "no flags",
+			dataset: func() *Dataset {
+				dataset := fixtureDataset(fixtureCountry("france", "France", "fr", "FRA", "250"))
+				dataset.Flags = map[string]Flag{}
+				return dataset
+			},
+			problem: Problem{severityWarning, "flag", "no flags are bundled, so /api/flags answers 404"},
+		},
+		{
+			name: "map path",
+			dataset: func() *Dataset {
+				dataset := fixtureDataset(fixtureCountry("france", "France", "fr", "FRA", "250"))
+				dataset.WorldMap.Paths = map[string]bool{"Gaul": true}
+				return dataset
+			},
+			problem: Problem{severityError, "map", `countriesMap entry "france" maps to "France" which has no path in map.svg`},
+		},
+		{
+			name: "no map",
+			dataset: func() *Dataset {
+				dataset := fixtureDataset(fixtureCountry("france", "France", "fr", "FRA", "250"))
+				dataset.WorldMap = nil
+				return dataset
+			},
+			problem: Problem{severityWarning, "map", "no map.svg is bundled, so /api/map.svg answers 404"},
+		},
+		{
+			name: "bounds the wrong way round",
+			dataset: func() *Dataset {
+				fiji := fixtureCountry("fiji", "Fiji", "fj", "FJI", "242")
+				fiji.Geo = Geo{
+					Latitude:  -17.66,
+					Longitude: 178.15,
+					Bounds:    Bounds{MinLatitude: -21.02, MinLongitude: -178.2, MaxLatitude: -12.47, MaxLongitude: 176.9},
+					Area:      18274,
+				}
+				return fixtureDataset(fiji)
+			},
+			problem: Problem{severityError, "geo", `bounding box of "fiji" spans 355.1 degrees of longitude`},
+		},
+		{
+			name: "duplicate alias",
+			dataset: func() *Dataset {
+				germany := fixtureCountry("germany", "Germany", "de", "DEU", "276")
+				germany.Aliases = []Alias{{Name: "france"}}
+				return fixtureDataset(fixtureCountry("france", "France", "fr", "FRA", "250"), germany)
+			},
+			problem: Problem{severityError, "duplicate", `"france" is used by both "france" and "germany"`},
+		},
+		{
+			name: "duplicate alpha-3 code",
+			dataset: func() *Dataset {
+				return fixtureDataset(
+					fixtureCountry("france", "France", "fr", "FRA", "250"),
+					fixtureCountry("germany", "Germany", "de", "FRA", "276"),
+				)
+			},
+			problem: Problem{severityError, "duplicate", `alpha-3 code "FRA" is used by both "france" and "germany"`},
+		},
+		{
+			name: "uppercase alias",
+			dataset: func() *Dataset {
+				france := fixtureCountry("france", "France", "fr", "FRA", "250")
+				france.Aliases = []Alias{{Name: "Gaul"}}
+				return fixtureDataset(france)
+			},
+			problem: Problem{severityError, "case", `alias "Gaul" of "france" is not lowercase`},
+		},
+		{
+			name: "lowercase alpha-3 code",
+			dataset: func() *Dataset {
+				return fixtureDataset(fixtureCountry("france", "France", "fr", "fra", "250"))
+			},
+			problem: Problem{severityError, "case", `alpha-3 code "fra" of "france" is not uppercase`},
+		},
+		{
+			name: "shared code",
+			dataset: func() *Dataset {
+				return fixtureDataset(
+					fixtureCountry("france", "France", "fr", "FRA", "250"),
+					fixtureCountry("reunion", "Reunion", "fr", "REU", "638"),
+				)
+			},
+			problem: Problem{severityWarning, "shared code", `"fr" is shared by France, Reunion`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := validateDataset(test.dataset())
+			for _, problem := range problems {
+				if problem == test.problem {
+					return
+				}
+			}
+			t.Errorf("got %v, want %v", problems, test.problem)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+)
+
+// DatasetVersion is the semantic version of the country data and its changelog, newest first.
+// The major version changes when the accepted countries change, so leaderboard scores stop
+// being comparable; the minor version when names or aliases are accepted; and the patch
+// version for any other correction.
+type DatasetVersion struct {
+	Version   string   `json:"version"`
+	Changelog []Change `json:"changelog"`
+}
+
+// Change is a changelog entry describing one released version of the country data.
+type Change struct {
+	Version string   `json:"version"`
+	Date    string   `json:"date"`
+	Changes []string `json:"changes"`
+}
+
+var semanticVersion = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
+
+// GetDatasetVersion gets the version and changelog of the country data.
+func GetDatasetVersion(writer http.ResponseWriter, request *http.Request) {
+	json.NewEncoder(writer).Encode(currentDataset().Version)
+}
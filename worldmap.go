@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// WorldMap is the SVG world map the frontend highlights countries on, with the id and name
+// attributes of its path elements.
+type WorldMap struct {
+	Contents []byte
+	ETag     string
+	Paths    map[string]bool
+}
+
+// loadWorldMap loads data/map.svg, returning nil when no map is bundled.
+func loadWorldMap(overrideDir string) (*WorldMap, error) {
+	contents, err := readOverridableFile(overrideDir, "data/map.svg")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	decoder := xml.NewDecoder(bytes.NewReader(contents))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("map.svg: %v", err)
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "path" {
+			continue
+		}
+		for _, attr := range element.Attr {
+			if attr.Name.Local == "id" || attr.Name.Local == "name" {
+				paths[attr.Value] = true
+			}
+		}
+	}
+
+	return &WorldMap{contents, fmt.Sprintf(`"%x"`, sha256.Sum256(contents)), paths}, nil
+}
+
+// serveSVG writes an SVG image, answering conditional requests from its ETag.
+func serveSVG(writer http.ResponseWriter, request *http.Request, name string, contents []byte, etag, cacheControl string) {
+	writer.Header().Set("Content-Type", "image/svg+xml")
+	writer.Header().Set("Cache-Control", cacheControl)
+	writer.Header().Set("ETag", etag)
+	http.ServeContent(writer, request, name, time.Time{}, bytes.NewReader(contents))
+}
+
+// GetWorldMap gets the SVG world map.
+func GetWorldMap(writer http.ResponseWriter, request *http.Request) {
+	worldMap := currentDataset().WorldMap
+	if worldMap == nil {
+		writer.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(writer, "no map bundled\n")
+		return
+	}
+	serveSVG(writer, request, "map.svg", worldMap.Contents, worldMap.ETag, "public, no-cache")
+}